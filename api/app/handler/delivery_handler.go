@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/internal/clients"
+	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ServeDeliveries godoc
+// @Summary      List webhook deliveries of a service
+// @Description  Returns the bundle-update webhooks that have not been delivered yet, newest first.
+// @Description  `pending` deliveries are retried with exponential backoff; `failed` deliveries exceeded `clients.delivery.max_age` and are kept until redelivered.
+// @Description  Every attempt is recorded with its time, HTTP status and error. Delivered webhooks are removed from the outbox.
+// @Description  Only the newest event per client is kept: enqueuing a new event removes the older deliveries to the same client, so versions never arrive out of order.
+//
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path  string true  "Service name (must be listed in config.clients.service)"
+// @Param        status  query string false "Filter by status" Enums(pending, failed)
+//
+// @Success      200 {object} deliveryListResponse "Deliveries"
+// @Failure      400 {object} appErr.HttpError "Invalid service or status parameter"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/deliveries [get]
+//
+// @Example Request:
+// GET /services/casb/deliveries?status=failed
+func (sh *ServiceHandler) ServeDeliveries(c *gin.Context) {
+	service := c.Param("service")
+
+	st := c.Query("status")
+	if st != "" && st != clients.DeliveryPending && st != clients.DeliveryFailed {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    fmt.Sprintf("invalid status %q (pending, failed)", st),
+		}, "invalid delivery status", zap.String("service", service), zap.String("status", st))
+		return
+	}
+
+	c.JSON(http.StatusOK, &deliveryListResponse{
+		Service:    service,
+		Deliveries: sh.Client.Outbox.List(service, st),
+	})
+}
+
+// Redeliver godoc
+// @Summary      Redeliver a webhook
+// @Description  Schedules a pending or failed delivery to be sent immediately. A failed delivery is retried again for `clients.delivery.max_age`.
+//
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        id      path string true "Delivery ID"
+//
+// @Success      202 {object} clients.Delivery "Accepted - The delivery will be sent"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter"
+// @Failure      404 {object} appErr.HttpError "Delivery not found"
+// @Failure      500 {object} appErr.HttpError "Failed to update the outbox"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/deliveries/{id}/redeliver [post]
+//
+// @Example Request:
+// POST /services/casb/deliveries/4f1c.../redeliver
+func (sh *ServiceHandler) Redeliver(c *gin.Context) {
+	service := c.Param("service")
+	id := c.Param("id")
+
+	d, err := sh.Client.Outbox.Redeliver(service, id, time.Now())
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_server_error"
+		if errors.Is(err, clients.ErrDeliveryNotFound) {
+			status, code = http.StatusNotFound, "not_found"
+		}
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   code,
+			Status: status,
+			Err:    err.Error(),
+		}, "failed to redeliver webhook", zap.Error(err), zap.String("service", service), zap.String("delivery", id))
+		return
+	}
+	sh.Info("webhook redelivery scheduled", zap.String("service", service), zap.String("delivery", id), zap.String("target", d.URL))
+
+	c.JSON(http.StatusAccepted, d)
+}
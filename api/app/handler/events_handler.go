@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/config"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"github.com/jjhwan-h/bundle-server/internal/events"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ServeEvents godoc
+// @Summary      Stream bundle events of a service
+// @Description  Opens a Server-Sent Events stream. A `bundle-published` event (type, version, etag) is sent as soon as a regular or delta bundle is published.
+// @Description  A `: heartbeat` comment is sent every `events.heartbeat` seconds to keep the connection open.
+// @Description  Reconnecting clients resume with the `Last-Event-ID` header (or `last_event_id` query). If events after that ID are no longer buffered, a `sync` event with the latest regular bundle is sent first.
+// @Description  Slow subscribers are disconnected, and all streams are closed when the server shuts down; clients should reconnect with `Last-Event-ID`.
+//
+// @Tags         service
+// @Produce      text/event-stream
+//
+// @Param        service       path   string true  "Service name (must be listed in config.clients.service)"
+// @Param        Last-Event-ID header string false "ID of the last received event"
+// @Param        last_event_id query  string false "Same as Last-Event-ID (for clients that cannot set headers)"
+//
+// @Success      200 {object} events.Event "Event stream"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/events [get]
+//
+// @Example Request:
+// GET /services/casb/events
+// Last-Event-ID: 1718000000000000
+func (sh *ServiceHandler) ServeEvents(c *gin.Context) {
+	service := c.Param("service")
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	lastID, _ := strconv.ParseInt(lastEventID, 10, 64) // 잘못된 값은 처음부터 구독
+
+	backlog, gap, ch, cancel := sh.Events.Subscribe(service, lastID)
+	defer cancel()
+
+	heartbeat := time.Duration(config.Cfg.Events.Heartbeat) * time.Second
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx 등 proxy buffering 비활성화
+	c.Status(http.StatusOK)
+
+	sh.Info("event stream opened",
+		zap.String("service", service),
+		zap.Int64("last_event_id", lastID),
+		zap.Bool("gap", gap),
+		zap.Int("backlog", len(backlog)),
+	)
+
+	// resume할 event가 buffer에서 사라진 경우 최신 bundle로 동기화
+	if gap {
+		if b, ok := sh.Client.Bundle[service]; ok && b.GetEtag() != "" {
+			writeEvent(c, events.TypeSync, events.Event{
+				Service:    service,
+				BundleType: bundle.TypeRegular,
+				Version:    bundle.Revision(b.Latest.GetMajor(), b.Latest.GetMinor()),
+				ETag:       b.GetEtag(),
+				Timestamp:  time.Now().UTC(),
+			})
+		}
+	}
+	for _, e := range backlog {
+		writeEvent(c, events.TypeBundlePublished, e)
+	}
+	c.Writer.Flush()
+
+	// TimeOutMiddleware의 timeout과 무관하게 연결이 끊기거나 서버가 종료될 때까지 유지
+	ctx, stop := longLivedContext(c)
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			sh.Debug("event stream closed", zap.String("service", service))
+			return
+		case e, ok := <-ch:
+			if !ok {
+				// 느린 구독자: 연결을 끊고 Last-Event-ID로 재연결하도록 함
+				sh.Warn("event stream subscriber too slow, disconnecting", zap.String("service", service))
+				return
+			}
+			writeEvent(c, events.TypeBundlePublished, e)
+		case <-ticker.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// id가 있으면 재연결 시 Last-Event-ID로 전달됨
+func writeEvent(c *gin.Context, typ string, e events.Event) {
+	ev := sse.Event{Event: typ, Data: e}
+	if e.ID != 0 {
+		ev.Id = strconv.FormatInt(e.ID, 10)
+	}
+	c.Render(-1, ev)
+}
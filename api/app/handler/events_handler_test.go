@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	contextkey "github.com/jjhwan-h/bundle-server/api/context"
+	"github.com/jjhwan-h/bundle-server/internal/events"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestServeEventsEndsOnShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sh := &ServiceHandler{Events: events.NewBroker(0), Logger: zap.NewNop()}
+	r := gin.New()
+	r.GET("/services/:service/events", sh.ServeEvents)
+
+	shutdown, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/services/casb/events", nil)
+	req = req.WithContext(context.WithValue(req.Context(), contextkey.ShutdownKey{}, shutdown))
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, req)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for sh.Events.Subscribers("casb") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriber was not registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	sh.Events.Publish(events.Event{Service: "casb", BundleType: "regular", Version: "v0.2", Timestamp: time.Now()})
+
+	// 서버 종료 시 연결을 끊어 Shutdown이 완료될 수 있도록
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("event stream must end on server shutdown")
+	}
+
+	if body := w.Body.String(); !strings.Contains(body, "event:"+events.TypeBundlePublished) || !strings.Contains(body, `"version":"v0.2"`) {
+		t.Fatalf("unexpected stream: %q", body)
+	}
+	if n := sh.Events.Subscribers("casb"); n != 0 {
+		t.Fatalf("subscriber must be removed, got %d", n)
+	}
+}
@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	contextkey "github.com/jjhwan-h/bundle-server/api/context"
+	"github.com/jjhwan-h/bundle-server/internal/auth"
+	"github.com/jjhwan-h/bundle-server/internal/clients"
+	"github.com/jjhwan-h/bundle-server/internal/decision"
+	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+	"github.com/jjhwan-h/bundle-server/internal/status"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OPA management API (status, decision log) 수신 핸들러
+
+// ReceiveStatus godoc
+// @Summary      Receive OPA status reports
+// @Description  Receives status updates from the OPA status plugin and records, for each instance, the active bundle revision, errors and last-seen time.
+// @Description  Only bundles whose name matches a service listed in `clients.service` and for which the caller has the `reader` role are recorded.
+// @Description  The instance is identified by `labels.id`; set `labels.client` to the registered webhook address to link the instance to a client.
+//
+// @Tags         opa
+// @Accept       json
+// @Produce      json
+//
+// @Param        status body object true "OPA status report"
+//
+// @Success      200 {object} httpResponse "OK - The status was recorded"
+// @Failure      400 {object} appErr.HttpError "Malformed status report"
+//
+// @Security     BearerAuth
+// @Router       /status [post]
+//
+// @Example Request:
+// POST /status
+// {"labels": {"id": "...", "client": "http://127.0.0.1:5556"}, "bundles": {"casb": {"name": "casb", "active_revision": "v0.3"}}}
+func (sh *ServiceHandler) ReceiveStatus(c *gin.Context) {
+	var st status.Status
+	if err := decodeOpaBody(c, &st); err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "failed to decode status report", zap.Error(err), zap.String("ip", c.ClientIP()))
+		return
+	}
+
+	// 다른 service의 rollout 상태를 조작하지 못하도록 reader 권한이 있는 service만 기록
+	readable := sh.readableService(c)
+	var denied []string
+	for name := range st.Bundles {
+		if sh.isService(name) && !readable(name) {
+			denied = append(denied, name)
+		}
+	}
+	if st.Bundle != nil && sh.isService(st.Bundle.Name) && !readable(st.Bundle.Name) {
+		denied = append(denied, st.Bundle.Name)
+	}
+	if len(denied) > 0 {
+		sh.Warn("status of bundles without a reader grant were dropped", zap.Strings("services", denied), zap.String("ip", c.ClientIP()))
+	}
+
+	updated := sh.Status.Update(&st, c.ClientIP(), readable, time.Now())
+
+	c.Set(contextkey.LogLevel, zap.DebugLevel) // OPA가 주기적으로 전송
+	sh.Debug("status report received",
+		zap.String("instance", st.Labels["id"]),
+		zap.String("ip", c.ClientIP()),
+		zap.Strings("services", updated),
+	)
+
+	c.JSON(http.StatusOK, &httpResponse{
+		Code:    "success",
+		Message: fmt.Sprintf("status of %d bundle(s) recorded.", len(updated)),
+		Status:  http.StatusOK,
+	})
+}
+
+// ServeRollout godoc
+// @Summary      Get bundle rollout state of a service
+// @Description  Joins the OPA status reports with the registered clients and the latest bundle version.
+// @Description  Each client is `active` (latest bundle activated), `stale` (older revision), `failing` (bundle error) or `unknown` (no report).
+// @Description  Reporting instances that cannot be linked to a registered client are listed in `unmatched`.
+//
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+//
+// @Success      200 {object} status.Rollout "Rollout state per client"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter"
+// @Failure      500 {object} appErr.HttpError "Failed to read the client registry"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/rollout [get]
+//
+// @Example Request:
+// GET /services/casb/rollout
+func (sh *ServiceHandler) ServeRollout(c *gin.Context) {
+	service := c.Param("service")
+
+	records, err := sh.Client.Get(c.Request.Context(), service)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read clients", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	c.JSON(http.StatusOK, status.NewRollout(
+		service,
+		sh.Client.Bundle[service].LatestVersion(),
+		clients.URLs(records),
+		sh.Status.Reports(service),
+	))
+}
+
+// ReceiveDecisionLogs godoc
+// @Summary      Receive OPA decision logs
+// @Description  Receives a (gzip-compressed) batch of decision log events from the OPA decision log plugin.
+// @Description  Each event is stored for the services of the bundles it references, or for the first segment of its path.
+// @Description  Events are only stored for services the caller has the `reader` role for; other events are dropped.
+// @Description  Events are appended to daily NDJSON files under `<opa_data_path>/decisions/<service>` and kept for `decision_logs.keep_days` days.
+//
+// @Tags         opa
+// @Accept       json
+// @Produce      json
+//
+// @Param        Content-Encoding header string false "gzip"
+// @Param        events body []decision.Event true "Decision log events"
+//
+// @Success      200 {object} httpResponse "OK - The events were stored"
+// @Failure      400 {object} appErr.HttpError "Malformed decision log batch"
+// @Failure      500 {object} appErr.HttpError "Internal server error while storing the events"
+//
+// @Security     BearerAuth
+// @Router       /logs [post]
+//
+// @Example Request:
+// POST /logs
+// Content-Encoding: gzip
+// [{"decision_id": "...", "path": "casb/authz/allow", "result": false, "bundles": {"casb": {"revision": "v0.3"}}, "timestamp": "..."}]
+func (sh *ServiceHandler) ReceiveDecisionLogs(c *gin.Context) {
+	var events []decision.Event
+	if err := decodeOpaBody(c, &events); err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "failed to decode decision logs", zap.Error(err), zap.String("ip", c.ClientIP()))
+		return
+	}
+
+	// 다른 service의 decision log를 기록하지 못하도록 reader 권한이 있는 service만 저장
+	readable := sh.readableService(c)
+
+	byService := map[string][]decision.Event{}
+	var dropped, denied int
+	for _, e := range events {
+		known := e.Services(sh.isService)
+		if len(known) == 0 {
+			dropped++
+			continue
+		}
+		// 참조하는 service 중 권한이 없는 service는 제외 (path로 다른 service에 기록되지 않도록 Services 이후에 확인)
+		var services []string
+		for _, service := range known {
+			if readable(service) {
+				services = append(services, service)
+			}
+		}
+		if len(services) == 0 {
+			denied++
+			continue
+		}
+		for _, service := range services {
+			byService[service] = append(byService[service], e)
+		}
+	}
+
+	for service, events := range byService {
+		if err := sh.Decisions.Append(c.Request.Context(), service, events); err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to store decision logs", zap.Error(err), zap.String("service", service))
+			return
+		}
+	}
+
+	c.Set(contextkey.LogLevel, zap.DebugLevel) // OPA가 주기적으로 전송
+	if dropped > 0 {
+		sh.Warn("decision logs without a known service were dropped", zap.Int("count", dropped), zap.String("ip", c.ClientIP()))
+	}
+	if denied > 0 {
+		sh.Warn("decision logs without a reader grant were dropped", zap.Int("count", denied), zap.String("ip", c.ClientIP()))
+	}
+
+	c.JSON(http.StatusOK, &httpResponse{
+		Code:    "success",
+		Message: fmt.Sprintf("%d decision(s) stored.", len(events)-dropped-denied),
+		Status:  http.StatusOK,
+	})
+}
+
+// ServeDecisions godoc
+// @Summary      Search decision logs of a service
+// @Description  Returns the stored decision log events of the service, newest first.
+// @Description  Each event carries the bundle revisions that produced it in `bundles`.
+//
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        from query string false "Start time (RFC3339)"
+// @Param        to query string false "End time (RFC3339)"
+// @Param        decision_id query string false "Decision ID"
+// @Param        path query string false "Decision path or its prefix (e.g. casb/authz)"
+// @Param        result query string false "Decision result as JSON (e.g. false)"
+// @Param        limit query int false "Maximum number of events (default 100, max 1000)"
+//
+// @Success      200 {object} decisionListResponse "Matching decision log events"
+// @Failure      400 {object} appErr.HttpError "Invalid service or query parameter"
+// @Failure      500 {object} appErr.HttpError "Internal server error while reading the decision logs"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/decisions [get]
+//
+// @Example Request:
+// GET /services/casb/decisions?result=false&from=2025-01-01T00:00:00Z
+func (sh *ServiceHandler) ServeDecisions(c *gin.Context) {
+	service := c.Param("service")
+
+	q, err := decisionQuery(c)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "invalid decision query", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	events, err := sh.Decisions.Search(service, q)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to search decision logs", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	c.JSON(http.StatusOK, &decisionListResponse{
+		Service:   service,
+		Decisions: events,
+	})
+}
+
+func decisionQuery(c *gin.Context) (decision.Query, error) {
+	q := decision.Query{
+		DecisionID: c.Query("decision_id"),
+		Path:       c.Query("path"),
+	}
+
+	for key, t := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		if v := c.Query(key); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return q, fmt.Errorf("invalid '%s' (RFC3339 expected): %w", key, err)
+			}
+			*t = parsed
+		}
+	}
+
+	if v := c.Query("result"); v != "" {
+		if !json.Valid([]byte(v)) {
+			b, _ := json.Marshal(v) // JSON이 아닌 경우 문자열로 비교
+			v = string(b)
+		}
+		q.Result = json.RawMessage(v)
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return q, fmt.Errorf("invalid 'limit': %q", v)
+		}
+		q.Limit = limit
+	}
+	return q, nil
+}
+
+func (sh *ServiceHandler) isService(name string) bool {
+	_, ok := sh.Client.Bundle[name]
+	return ok
+}
+
+// 인증된 요청인 경우 caller가 reader 권한을 가진 service만 허용 (인증 비활성화 시 모든 service)
+func (sh *ServiceHandler) readableService(c *gin.Context) func(string) bool {
+	var id *auth.Identity
+	if v, ok := c.Get(contextkey.Identity); ok {
+		id, _ = v.(*auth.Identity)
+	}
+	return func(name string) bool {
+		return sh.isService(name) && (id == nil || id.Can(name, auth.RoleReader))
+	}
+}
+
+// OPA는 decision log(및 설정에 따라 status)를 gzip으로 압축해서 전송
+func decodeOpaBody(c *gin.Context, v any) error {
+	var r io.Reader = c.Request.Body
+	if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			return fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode request body: %w", err)
+	}
+	return nil
+}
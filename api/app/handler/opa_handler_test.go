@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	contextkey "github.com/jjhwan-h/bundle-server/api/context"
+	"github.com/jjhwan-h/bundle-server/internal/auth"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"github.com/jjhwan-h/bundle-server/internal/clients"
+	"github.com/jjhwan-h/bundle-server/internal/decision"
+	"github.com/jjhwan-h/bundle-server/internal/status"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestReceiveOpaReportsRequiresGrant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sh := &ServiceHandler{
+		Client:    &clients.Client{Bundle: map[string]*bundle.Bundle{"casb": {}, "ztna": {}}},
+		Status:    status.NewStore(),
+		Decisions: decision.NewStore(t.TempDir(), 0),
+		Logger:    zap.NewNop(),
+	}
+	// casb:reader token
+	id := &auth.Identity{Subject: "opa-casb", Grants: map[string]auth.Role{"casb": auth.RoleReader}}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(contextkey.Identity, id) })
+	r.POST("/status", sh.ReceiveStatus)
+	r.POST("/logs", sh.ReceiveDecisionLogs)
+
+	post := func(path, body string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s: unexpected status %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	post("/status", `{"labels":{"id":"opa-1"},"bundles":{"casb":{"name":"casb","active_revision":"v0.1"},"ztna":{"name":"ztna","active_revision":"v9.9"}}}`)
+	if n := len(sh.Status.Reports("casb")); n != 1 {
+		t.Fatalf("expected casb report, got %d", n)
+	}
+	if n := len(sh.Status.Reports("ztna")); n != 0 {
+		t.Fatalf("status of ztna must not be recorded without a grant, got %d", n)
+	}
+
+	post("/logs", `[
+		{"decision_id":"1","path":"casb/authz/allow","result":true,"bundles":{"casb":{"revision":"v0.1"},"ztna":{"revision":"v0.1"}}},
+		{"decision_id":"2","path":"ztna/authz/allow","result":true},
+		{"decision_id":"3","path":"casb/authz/allow","result":true,"bundles":{"ztna":{"revision":"v0.1"}}}
+	]`)
+	casb, err := sh.Decisions.Search("casb", decision.Query{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(casb) != 1 || casb[0].DecisionID != "1" {
+		t.Fatalf("unexpected casb decisions: %+v", casb)
+	}
+	ztna, err := sh.Decisions.Search("ztna", decision.Query{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(ztna) != 0 {
+		t.Fatalf("decisions of ztna must not be stored without a grant: %+v", ztna)
+	}
+}
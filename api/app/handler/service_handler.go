@@ -1,18 +1,20 @@
 package handler
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,8 +22,14 @@ import (
 	contextkey "github.com/jjhwan-h/bundle-server/api/context"
 	"github.com/jjhwan-h/bundle-server/config"
 	"github.com/jjhwan-h/bundle-server/domain/usecase"
+	"github.com/jjhwan-h/bundle-server/internal/auth"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
 	"github.com/jjhwan-h/bundle-server/internal/clients"
+	"github.com/jjhwan-h/bundle-server/internal/decision"
 	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+	"github.com/jjhwan-h/bundle-server/internal/events"
+	"github.com/jjhwan-h/bundle-server/internal/policy"
+	"github.com/jjhwan-h/bundle-server/internal/status"
 	"github.com/jjhwan-h/bundle-server/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -29,23 +37,44 @@ import (
 	"go.uber.org/zap"
 )
 
+const opaBundleMediaType = "application/vnd.openpolicyagent.bundles"
+
 type ServiceHandler struct {
 	CasbUsecase usecase.CasbUsecase
 	Client      *clients.Client
+	History     map[string]*policy.History // service별 정책 변경 이력
+	Status      *status.Store              // OPA instance별 상태 보고
+	Decisions   *decision.Store            // OPA decision log
+	Events      *events.Broker             // bundle 게시 event (SSE)
 	*zap.Logger
 }
 
+func NewPolicyHistory(services map[string][]string) map[string]*policy.History {
+	history := make(map[string]*policy.History, len(services))
+	for service := range services {
+		history[service] = policy.NewHistory(filepath.Join(config.Cfg.OpaDataPath, service, "history"))
+	}
+	return history
+}
+
 // @title service api
 // @version 1.0
 // @BasePath /services
+//
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API key or JWT as "Bearer <token>". Required when auth.token_file or auth.jwks_file is configured.
 
 // BuildDataNBundles godoc
 // @Summary      Trigger policy DB update and generate OPA bundles
 // @Description  Triggers OPA bundle regeneration.
 // @Description  1. Builds `data.json` for the given service
 // @Description  2. Compares with previous version to generate `patch.json`
-// @Description  3. If changes are found, creates `delta.tar.gz` and `regular-vX.X.tar.gz` bundles
-// @Description  4. Sends webhook POST /hooks/bundle-update?type=delta to notify OPA SDK clients
+// @Description  3. If changes are found, creates `delta-vX.X-vY.Y.tar.gz` and `regular-vY.Y.tar.gz` bundles
+// @Description  4. Runs the service's `*_test.rego` files against the new regular bundle; the version is not published if a test fails
+// @Description  5. Sends webhook POST /hooks/bundle-update?type=delta to notify OPA SDK clients
+// @Description     (JSON body with service, type, version, etag and timestamp; signed with X-Signature when a client secret is configured)
 //
 // @Tags         service
 // @Accept       json
@@ -55,9 +84,11 @@ type ServiceHandler struct {
 // @Success      202 {object} httpResponse "Accepted - Bundles generated and notification will be sent to OPA clients"
 // @Success      200 {object} httpResponse "OK - No changes detected in data.json (no new bundles created)"
 // @Failure      400 {object} appErr.HttpError "Invalid service parameter"
+// @Failure      422 {object} policyErrorResponse "Policy tests failed against the new bundle"
 // @Failure      500 {object} appErr.HttpError "Internal server error during data/bundle generation"
 // @Failure      501 {object} appErr.HttpError "Service not yet supported"
 //
+// @Security     BearerAuth
 // @Router       /services/{service}/data/trigger [post]
 //
 // @Example Request:
@@ -99,6 +130,11 @@ func (sh *ServiceHandler) BuildDataNBundles(c *gin.Context) {
 		return
 	}
 
+	// 이전 data.json 조회부터 index 기록까지 같은 service의 게시를 직렬화
+	b := sh.Client.Bundle[service]
+	unlock := b.LockPublish()
+	defer unlock()
+
 	oldData, err := getDataJson(dataPath)
 	if err != nil {
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
@@ -119,13 +155,43 @@ func (sh *ServiceHandler) BuildDataNBundles(c *gin.Context) {
 		}, "failed to build patch.json", zap.Error(err), zap.String("service", service))
 		return
 	}
-	// delta-bundle 생성
+
+	major, minor := b.Latest.GetMajor(), b.Latest.GetMinor()
+	nMajor, nMinor := b.NextVersion()
+	revision := bundle.Revision(nMajor, nMinor)
+	deltaFile := bundle.DeltaFileName(major, minor, nMajor, nMinor)
+	deltaPath := fmt.Sprintf("%s/%s/%s", config.Cfg.OpaDataPath, service, deltaFile)
+	regularPath := fmt.Sprintf("%s/%s/%s", config.Cfg.OpaDataPath, service, bundle.RegularFileName(nMajor, nMinor))
+
+	// 게시하지 못한 경우(policy test 실패 등) 생성한 파일 삭제
+	// 남아있으면 같은 버전 번호를 재사용하는 다음 trigger의 bundle과 섞임
+	var deltaRecorded, published bool
+	defer func() {
+		if published {
+			return
+		}
+		if !deltaRecorded {
+			for _, p := range []string{deltaPath, patchPath} {
+				if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+					sh.Warn("failed to remove unpublished delta bundle", zap.String("path", p), zap.Error(err))
+				}
+			}
+		}
+		if err := os.Remove(regularPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			sh.Warn("failed to remove unpublished regular bundle", zap.String("path", regularPath), zap.Error(err))
+		}
+	}()
+
+	// delta-bundle 생성 (vX.Y -> 다음 버전 단일 step)
 	err = buildDeltaBundle(
 		c,
 		patch,
 		patchPath,
-		fmt.Sprintf("%s/%s/delta.tar.gz", config.Cfg.OpaDataPath, service),
+		deltaPath,
+		b.NewManifest(revision, bundle.TypeDelta),
+		b.Signer,
 	)
+	deltaBuilt := err == nil
 	if err != nil {
 		// data.json 없음: 로깅만 하고 아래로 진행
 		if errors.Is(err, os.ErrNotExist) {
@@ -151,43 +217,81 @@ func (sh *ServiceHandler) BuildDataNBundles(c *gin.Context) {
 	}
 	sh.Info("Delta Bundle created successfully", zap.String("service", service))
 
-	nMajor, nMinor := sh.Client.Bundle[service].Latest.NextVersion()
+	tests, err := loadPolicyTests(service)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read policy tests", zap.Error(err), zap.String("service", service))
+		return
+	}
+
 	// 일반-bundle 생성
 	// opa-sdk-client들 초기 실행 시 변경사항이 반영된 일반-bundle 필요
+	// policy test 실패 시 새 버전을 게시하지 않음 (delta-bundle도 index에 기록하지 않음)
 	err = buildBundle(
 		c,
 		data,
 		dataPath,
-		fmt.Sprintf("%s/%s/regular-v%d.%d.tar.gz", config.Cfg.OpaDataPath, service, nMajor, nMinor),
+		regularPath,
+		b.NewManifest(revision, bundle.TypeRegular),
+		b.Signer,
+		func(files []bundle.File) error { return policy.RunTests(c, files, tests) },
 	)
 	if err != nil {
+		if sh.handlePolicyError(c, service, err) {
+			return
+		}
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
 			Code:   "internal_server_error",
 			Status: http.StatusInternalServerError,
 			Err:    err.Error(),
 		}, "failed to build regular bundle", zap.Error(err), zap.String("service", service))
 		return
-	} else {
-		sh.Info("Regular Bundle created successfully", zap.String("service", service))
-		sh.Client.Bundle[service].Latest.IncrementVersion()
+	}
+	sh.Info("Regular Bundle created successfully", zap.String("service", service))
+
+	if deltaBuilt {
+		_, err = b.Record(c, bundle.IndexEntry{
+			Version: revision,
+			Type:    bundle.TypeDelta,
+			From:    bundle.Revision(major, minor),
+			File:    deltaFile,
+			Trigger: bundle.TriggerData,
+		})
+		if err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to record bundle index", zap.Error(err), zap.String("service", service))
+			return
+		}
+		deltaRecorded = true
 	}
 
-	// Etag update
-	_, err = sh.Client.Bundle[service].ETagFromFile()
+	// index 기록 (Latest, ETag 갱신)
+	entry, err := b.Record(c, bundle.IndexEntry{
+		Version: revision,
+		Type:    bundle.TypeRegular,
+		File:    bundle.RegularFileName(nMajor, nMinor),
+		Trigger: bundle.TriggerData,
+	})
 	if err != nil {
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
 			Code:   "internal_server_error",
 			Status: http.StatusInternalServerError,
 			Err:    err.Error(),
-		}, "failed to update etag(hash)", zap.Error(err), zap.String("service", service))
+		}, "failed to record bundle index", zap.Error(err), zap.String("service", service))
 		return
 	}
+	published = true
+	sh.Info("Bundle version recorded", zap.String("service", service), zap.String("version", entry.Version), zap.Int64("revision", entry.Revision))
 
-	go func(major int, minor int8) {
-		err := sh.Client.Hook("hooks/bundle-update?type=delta", service)
-		if err != nil {
-			sh.Error("failed to event notification", zap.Error(err))
-		}
+	go func(major, minor int) {
+		event := clients.NewHookEvent(service, bundle.TypeDelta, major, minor, b.GetEtag(), time.Now())
+		sh.notifyClients(clients.DefaultHookPath+"?type=delta", event)
 	}(nMajor, nMinor)
 
 	c.JSON(http.StatusAccepted, &httpResponse{
@@ -200,6 +304,7 @@ func (sh *ServiceHandler) BuildDataNBundles(c *gin.Context) {
 // CreateBundle godoc
 // @Summary      Trigger policy.rego update and generate regular OPA bundle
 // @Description  Triggers regeneration of the regular bundle (policy.rego and related files).
+// @Description  The service's `*_test.rego` files are run against the new bundle; the version is not published if a test fails.
 // @Description  If changes are detected, sends a webhook notification to OPA SDK clients via POST /hooks/bundle-update.
 //
 // @Tags         service
@@ -209,8 +314,10 @@ func (sh *ServiceHandler) BuildDataNBundles(c *gin.Context) {
 //
 // @Success      202 {object} httpResponse "Accepted - Regular bundle was generated and notification will be sent to OPA clients"
 // @Failure      400 {object} appErr.HttpError "Invalid service parameter"
+// @Failure      422 {object} policyErrorResponse "Policy tests failed against the new bundle"
 // @Failure      500 {object} appErr.HttpError "Internal server error during regular bundle generation"
 //
+// @Security     BearerAuth
 // @Router       /services/{service}/policy/trigger [post]
 //
 // @Example Request:
@@ -218,43 +325,65 @@ func (sh *ServiceHandler) BuildDataNBundles(c *gin.Context) {
 func (sh *ServiceHandler) CreateBundle(c *gin.Context) {
 	service := c.Param("service")
 
-	// IncrementVersion() 호출 전까지 race-condition발생 가능하므로 regular-bundle로 .lock파일 유지
-	nMajor, nMinor := sh.Client.Bundle[service].Latest.NextVersion()
+	b := sh.Client.Bundle[service]
+
+	// Record() 호출 전까지 같은 버전을 발급하지 않도록 게시를 직렬화
+	unlock := b.LockPublish()
+	defer unlock()
+	nMajor, nMinor := b.NextVersion()
+
+	tests, err := loadPolicyTests(service)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read policy tests", zap.Error(err), zap.String("service", service))
+		return
+	}
 
-	err := createBundle(
+	err = createBundle(
 		c.Request.Context(),
-		fmt.Sprintf("%s/%s/regular-v%d.%d.tar.gz", config.Cfg.OpaDataPath, service, nMajor, nMinor),
-		fmt.Sprintf("%s/%s", config.Cfg.OpaDataPath, service),
+		fmt.Sprintf("%s/%s/%s", config.Cfg.OpaDataPath, service, bundle.RegularFileName(nMajor, nMinor)),
+		fmt.Sprintf("%s/%s/regular", config.Cfg.OpaDataPath, service),
+		b.NewManifest(bundle.Revision(nMajor, nMinor), bundle.TypeRegular),
+		b.Signer,
+		func(files []bundle.File) error { return policy.RunTests(c.Request.Context(), files, tests) },
 	)
 
 	if err != nil {
+		if sh.handlePolicyError(c, service, err) {
+			return
+		}
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
 			Code:   "internal_server_error",
 			Status: http.StatusInternalServerError,
 			Err:    err.Error(),
 		}, "failed to build regular bundle", zap.Error(err), zap.String("service", service))
 		return
-	} else {
-		sh.Info("Regular Bundle created successfully", zap.String("service", service))
-		sh.Client.Bundle[service].Latest.IncrementVersion()
 	}
-
-	// Etag update
-	_, err = sh.Client.Bundle[service].ETagFromFile()
+	sh.Info("Regular Bundle created successfully", zap.String("service", service))
+
+	// index 기록 (Latest, ETag 갱신)
+	entry, err := b.Record(c.Request.Context(), bundle.IndexEntry{
+		Version: bundle.Revision(nMajor, nMinor),
+		Type:    bundle.TypeRegular,
+		File:    bundle.RegularFileName(nMajor, nMinor),
+		Trigger: bundle.TriggerPolicy,
+	})
 	if err != nil {
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
 			Code:   "internal_server_error",
 			Status: http.StatusInternalServerError,
 			Err:    err.Error(),
-		}, "failed to update etag(hash)", zap.Error(err), zap.String("service", service))
+		}, "failed to record bundle index", zap.Error(err), zap.String("service", service))
 		return
 	}
+	sh.Info("Bundle version recorded", zap.String("service", service), zap.String("version", entry.Version), zap.Int64("revision", entry.Revision))
 
-	go func(major int, minor int8) {
-		err := sh.Client.Hook("hooks/bundle-update", service)
-		if err != nil {
-			sh.Error("failed to event notification", zap.Error(err))
-		}
+	go func(major, minor int) {
+		event := clients.NewHookEvent(service, bundle.TypeRegular, major, minor, b.GetEtag(), time.Now())
+		sh.notifyClients(clients.DefaultHookPath, event)
 	}(nMajor, nMinor)
 
 	c.JSON(http.StatusAccepted, &httpResponse{
@@ -265,312 +394,1605 @@ func (sh *ServiceHandler) CreateBundle(c *gin.Context) {
 }
 
 // RegisterPolicy godoc
-// @Summary      Upload a policy.rego file
-// @Description  Uploads a `policy.rego` file via multipart/form-data and saves it into the service-specific bundle directory.
+// @Summary      Upload policy modules
+// @Description  Uploads one or more Rego modules via multipart/form-data and saves them into the service-specific bundle directory.
+// @Description  Each `file` field is saved under the matching `path` field (or its file name), so modules can be split into subdirectories.
+// @Description  A `.tar.gz` file is extracted with its relative paths preserved.
+// @Description  `*_test.rego` files are stored separately and only used by the pre-publish policy tests.
 // @Description  Only services defined in `clients.service` of the config file are allowed.
+// @Description  The whole policy set is compiled with the service's current data.json before it is saved.
+// @Description  Packages outside the service's bundle roots are rejected.
 //
 // @Tags         service
 // @Accept       multipart/form-data
 // @Produce      json
 //
 // @Param        service path string true "Service name (must be listed in config.clients.service)"
-// @Param        file formData file true "policy.rego file to upload"
+// @Param        file formData file true "Rego module or tar.gz of modules (repeatable)"
+// @Param        path formData string false "Relative path of the file at the same position (repeatable, e.g. lib/util.rego)"
 //
-// @Success      201 {object} httpResponse "Created - The policy.rego file was saved successfully"
-// @Failure      400 {object} appErr.HttpError "Invalid service parameter or malformed request"
+// @Success      201 {object} httpResponse "Created - The policy files were saved successfully"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter, path or malformed request"
+// @Failure      422 {object} policyErrorResponse "Policy failed to compile or its package is outside the bundle roots"
 // @Failure      500 {object} appErr.HttpError "Internal server error while saving the policy file"
 //
+// @Security     BearerAuth
 // @Router       /services/{service}/policy [post]
 //
 // @Example Request:
 // POST /services/casb/policy
 // Content-Type: multipart/form-data
 // Form field: file = policy.rego
+// Form field: file = util.rego, path = lib/util.rego
 func (sh *ServiceHandler) RegisterPolicy(c *gin.Context) {
 	service := c.Param("service")
-	policyPath := fmt.Sprintf("%s/%s/regular/policy.rego", config.Cfg.OpaDataPath, service)
 
-	fileHeader, err := c.FormFile("file")
-	if err != nil {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["file"]) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no file in form field 'file'")
+		}
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
-			Code:   "internal_server_error",
-			Status: http.StatusInternalServerError,
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
 			Err:    err.Error(),
 		}, "failed to parse form file", zap.Error(err), zap.String("service", service))
 		return
 	}
 
-	file, err := fileHeader.Open()
+	uploads, err := readPolicyUploads(form.File["file"], form.Value["path"])
 	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_server_error"
+		if errors.Is(err, policy.ErrInvalidPath) {
+			status, code = http.StatusBadRequest, "bad_request"
+		}
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
-			Code:   "internal_server_error",
-			Status: http.StatusInternalServerError,
+			Code:   code,
+			Status: status,
 			Err:    err.Error(),
-		}, "failed to open form file", zap.Error(err), zap.String("service", service))
+		}, "failed to read form file", zap.Error(err), zap.String("service", service))
 		return
 	}
-	defer file.Close()
 
-	err = utils.SaveToFileWithLock(c.Request.Context(), file, policyPath)
+	modules, tests, err := loadPolicySet(service)
 	if err != nil {
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
 			Code:   "internal_server_error",
 			Status: http.StatusInternalServerError,
 			Err:    err.Error(),
-		}, "failed to save form file", zap.Error(err), zap.String("service", service))
+		}, "failed to read policy modules", zap.Error(err), zap.String("service", service))
+		return
+	}
+	if !sh.initPolicyHistory(c, service, modules, tests) {
+		return
+	}
+	for name, src := range uploads {
+		if policy.IsTestFile(name) {
+			tests[name] = src
+		} else {
+			modules[name] = src
+		}
+	}
+
+	// 저장 전 현재 data.json과 함께 컴파일하여 OPA client에서 활성화 실패할 정책 거부
+	if !sh.validatePolicy(c, service, modules, tests) {
+		return
+	}
+
+	for name, src := range uploads {
+		err = utils.SaveToFileWithLock(c.Request.Context(), bytes.NewReader(src), policyFilePath(service, name))
+		if err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to save form file", zap.Error(err), zap.String("service", service), zap.String("path", name))
+			return
+		}
+		sh.Info("policy file created successfully", zap.String("service", service), zap.String("path", name))
+	}
+
+	if !sh.recordPolicyHistory(c, service, policy.Revision{
+		Action: policy.ActionUpload,
+		Files:  sortedNames(uploads),
+	}, modules, tests) {
 		return
-	} else {
-		sh.Info("policy.rego created successfully", zap.String("service", service))
 	}
 
 	c.JSON(http.StatusCreated, &httpResponse{
 		Code:    "success",
-		Message: "The policy file was generated successfully.",
+		Message: fmt.Sprintf("%d policy file(s) were saved successfully.", len(uploads)),
 		Status:  http.StatusCreated,
 	})
 }
 
-// ServeBundle godoc
-// @Summary      Download OPA bundle file
-// @Description  Downloads an OPA bundle file (.tar.gz) for the specified service.
-// @Description  By default, the latest **regular** bundle is served.
-// @Description  To download a **delta** bundle, use the query `?type=delta`.
-// @Description  To request a specific version of the regular bundle, use the query `?version=X.Y`.
-// @Description  Supports ETag validation using the `If-None-Match` header.
+// ServePolicy godoc
+// @Summary      Download a policy module
+// @Description  Returns the content of a Rego module of the service.
+// @Description  If the path is empty, returns the list of modules and test files.
 //
 // @Tags         service
-// @Produce      application/gzip
+// @Produce      plain
+// @Produce      json
 //
 // @Param        service path string true "Service name (must be listed in config.clients.service)"
-// @Param        type query string false "Bundle type: 'regular' (default) or 'delta'"
-// @Param        version query string false "Regular bundle version in format 'X.Y' (e.g., 1.2)"
-//
-// @Success      200 {file} file "The requested bundle file (.tar.gz)"
-// @Success      304 {object} httpResponse "Not Modified - Client already has the latest bundle"
-// @Failure      400 {object} appErr.HttpError "Invalid service parameters or file not found"
-// @Failure      500 {object} appErr.HttpError "Internal server error while serving the bundle"
+// @Param        path path string true "Relative path of the module (e.g. lib/util.rego)"
 //
-// @Header       200 {string} ETag "ETag header containing current bundle hash"
+// @Success      200 {string} string "Content of the module"
+// @Success      200 {object} policyListResponse "List of modules (empty path)"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter or path"
+// @Failure      404 {object} appErr.HttpError "Module not found"
+// @Failure      500 {object} appErr.HttpError "Internal server error while reading the module"
 //
-// @Router       /services/{service}/bundle [get]
+// @Security     BearerAuth
+// @Router       /services/{service}/policy/{path} [get]
 //
 // @Example Request:
-// GET /services/casb/bundle
-// GET /services/casb/bundle?type=delta
-// GET /services/casb/bundle?version=1.3
-func (sh *ServiceHandler) ServeBundle(c *gin.Context) {
-	var path string
-	var filename string
-	var etag string
-
+// GET /services/casb/policy/
+// GET /services/casb/policy/lib/util.rego
+func (sh *ServiceHandler) ServePolicy(c *gin.Context) {
 	service := c.Param("service")
-	version := c.Query("version")
-	t := c.Query("type")
-
-	major := sh.Client.Bundle[service].Latest.GetMajor()
-	minor := sh.Client.Bundle[service].Latest.GetMinor()
-
-	switch t {
-	case "delta":
-		path = fmt.Sprintf("%s/%s/delta.tar.gz", config.Cfg.OpaDataPath, service)
-		filename = fmt.Sprintf("%s_delta.tar.gz", service)
-	case "", "regular": // type이 비어있거나 regular인 경우 => regular-bundle 리턴
 
-		// If-Non-Match 헤더와 비교
-		etag = sh.Client.Bundle[service].GetEtag()
-		clientEtag := c.GetHeader("If-None-Match")
+	// gin은 catch-all과 같은 위치의 고정 경로를 허용하지 않으므로 여기서 분기
+	switch strings.Trim(c.Param("path"), "/") {
+	case "history":
+		sh.ServePolicyHistory(c)
+		return
+	case "diff":
+		sh.ServePolicyDiff(c)
+		return
+	}
 
-		sh.Debug("etag", zap.String("etag", etag), zap.String("clientEtag", clientEtag))
-		if etag == clientEtag {
-			c.Set(contextkey.LogLevel, zap.InfoLevel)
-			c.JSON(http.StatusNotModified, &httpResponse{
-				Code:    "not_modified",
-				Message: "To request the latest bundle, please omit the version query parameter.",
-				Status:  http.StatusNotModified,
-			})
+	if strings.Trim(c.Param("path"), "/") == "" {
+		modules, tests, err := loadPolicySet(service)
+		if err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to read policy modules", zap.Error(err), zap.String("service", service))
 			return
 		}
 
-		s := strings.Split(version, ".")
+		c.JSON(http.StatusOK, &policyListResponse{
+			Service: service,
+			Modules: sortedNames(modules),
+			Tests:   sortedNames(tests),
+		})
+		return
+	}
 
-		// version이 비어있는경우 또는 latest 를 요청하는 경우
-		if version == "" ||
-			(s[0] == strconv.Itoa(major) && s[1] == strconv.Itoa(int(minor))) {
-			path = fmt.Sprintf("%s/%s/regular-v%d.%d.tar.gz", config.Cfg.OpaDataPath, service, major, minor)
-			filename = fmt.Sprintf("%s_regular-v%d.%d.tar.gz", service, major, minor)
-			c.Header("ETag", etag) // 최신번들 요청일 경우에만 삽입
-		} else {
-			path = fmt.Sprintf("%s/%s/regular-v%s.%s.tar.gz", config.Cfg.OpaDataPath, service, s[0], s[1])
-			filename = fmt.Sprintf("%s_regular-v%s.%s.tar.gz", service, s[0], s[1])
-		}
-	default:
-		sh.Info("Invalid bundle type, defaulting to regular bundle",
-			zap.String("requested_type", t),
-			zap.String("service", service),
-		)
-		path = fmt.Sprintf("%s/%s/regular-v%d.%d.tar.gz", config.Cfg.OpaDataPath, service, major, minor)
-		filename = fmt.Sprintf("%s_regular-v%d.%d.tar.gz", service, major, minor)
+	name, ok := sh.policyPathParam(c, service)
+	if !ok {
+		return
 	}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	data, err := os.ReadFile(policyFilePath(service, name))
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_server_error"
+		if errors.Is(err, os.ErrNotExist) {
+			status, code = http.StatusNotFound, "not_found"
+		}
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
-			Code:   "bad_request",
-			Status: http.StatusBadRequest,
+			Code:   code,
+			Status: status,
 			Err:    err.Error(),
-		}, "bundle file not found", zap.Error(err), zap.String("service", service))
+		}, "failed to read policy module", zap.Error(err), zap.String("service", service), zap.String("path", name))
 		return
 	}
 
-	sh.Info("serve bundle", zap.String("name", filename))
-
-	mime := mime.TypeByExtension(filepath.Ext(filename))
-	c.Header("Content-Type", mime)
-	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=%s", filename))
-	http.ServeFile(c.Writer, c.Request, path)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", data)
 }
 
-// RegisterClients godoc
-// @Summary      Register OPA webhook client addresses
-// @Description  Registers one or more OPA SDK client addresses for the specified service.
-// @Description  Accepts a JSON array of client URLs (e.g., IP or domain).
-// @Description  These clients will be notified via webhook when a new bundle is available.
+// DeletePolicy godoc
+// @Summary      Delete a policy module
+// @Description  Deletes a Rego module of the service.
+// @Description  The remaining policy set is compiled first, so a module still imported by others cannot be deleted.
 //
 // @Tags         service
-// @Accept       json
 // @Produce      json
 //
 // @Param        service path string true "Service name (must be listed in config.clients.service)"
-// @Param        clients body []string true "List of OPA client addresses (IP or domain)"
+// @Param        path path string true "Relative path of the module (e.g. lib/util.rego)"
 //
-// @Success      200 {object} httpResponse "Clients registered successfully"
-// @Failure      400 {object} appErr.HttpError "Invalid service parameters, JSON format or no clients provided"
-// @Failure      409 {object} appErr.HttpError "Conflict - one or more clients already registered or registration failed"
+// @Success      200 {object} httpResponse "OK - The module was deleted"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter or path"
+// @Failure      404 {object} appErr.HttpError "Module not found"
+// @Failure      422 {object} policyErrorResponse "The remaining policy set fails to compile"
+// @Failure      500 {object} appErr.HttpError "Internal server error while deleting the module"
 //
-// @Router       /services/{service}/clients [post]
+// @Security     BearerAuth
+// @Router       /services/{service}/policy/{path} [delete]
 //
 // @Example Request:
-// POST /services/casb/clients
-// Content-Type: application/json
-// [
-//
-//	"http://127.0.0.1:5556",
-//	"http://opa-client.k8s.local:8181"
-//
-// ]
-func (sh *ServiceHandler) RegisterClients(c *gin.Context) {
+// DELETE /services/casb/policy/lib/util.rego
+func (sh *ServiceHandler) DeletePolicy(c *gin.Context) {
 	service := c.Param("service")
 
-	var clients []string
+	name, ok := sh.policyPathParam(c, service)
+	if !ok {
+		return
+	}
 
-	err := c.ShouldBindJSON(&clients)
+	modules, tests, err := loadPolicySet(service)
 	if err != nil {
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
-			Code:   "bad_request",
-			Status: http.StatusBadRequest,
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
 			Err:    err.Error(),
-		}, "Invalid reqeust body. Please check the JSON format", zap.Error(err), zap.String("service", service))
+		}, "failed to read policy modules", zap.Error(err), zap.String("service", service))
 		return
 	}
 
-	if len(clients) == 0 {
+	set := modules
+	if policy.IsTestFile(name) {
+		set = tests
+	}
+	if _, ok := set[name]; !ok {
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
-			Code:   "bad_request",
-			Status: http.StatusBadRequest,
-			Err:    "no clients found",
-		}, "no clients found", zap.String("service", service))
+			Code:   "not_found",
+			Status: http.StatusNotFound,
+			Err:    fmt.Sprintf("policy module not found: %s", name),
+		}, "policy module not found", zap.String("service", service), zap.String("path", name))
+		return
+	}
+	if !sh.initPolicyHistory(c, service, modules, tests) {
+		return
+	}
+	delete(set, name)
+
+	if !sh.validatePolicy(c, service, modules, tests) {
 		return
 	}
 
-	if err = sh.Client.AddHookClient(clients, service); err != nil {
+	if err := os.Remove(policyFilePath(service, name)); err != nil {
 		appErr.HandleError(c, sh.Logger, appErr.HttpError{
-			Code:   "conflict",
-			Status: http.StatusConflict,
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
 			Err:    err.Error(),
-		}, "client already exists", zap.Error(err), zap.String("service", service))
+		}, "failed to delete policy module", zap.Error(err), zap.String("service", service), zap.String("path", name))
 		return
 	}
+	sh.Info("policy file deleted successfully", zap.String("service", service), zap.String("path", name))
 
-	sh.Info("client address has been successfully registerd", zap.String("service", service))
+	if !sh.recordPolicyHistory(c, service, policy.Revision{
+		Action: policy.ActionDelete,
+		Files:  []string{name},
+	}, modules, tests) {
+		return
+	}
 
-	c.JSON(http.StatusOK, httpResponse{
-		Code:    "client_registered",
-		Message: "Client address has been successfully registered.",
+	c.JSON(http.StatusOK, &httpResponse{
+		Code:    "success",
+		Message: fmt.Sprintf("%s was deleted successfully.", name),
 		Status:  http.StatusOK,
 	})
 }
 
-// ServeClients godoc
-// @Summary      Get all registered OPA clients
-// @Description  Returns a map of all registered OPA clients grouped by service.
+// ServePolicyHistory godoc
+// @Summary      List policy revisions
+// @Description  Returns every recorded change of the service's policy set (oldest first).
+// @Description  Each upload, delete and restore is stored as an immutable revision with its author, timestamp and snapshot hash.
+// @Description  The author is the authenticated subject, or the `X-User` header / client IP if authentication is disabled.
+//
 // @Tags         service
 // @Produce      json
 //
-// @Success      200 {object} clientGroupResponse "Map of service name to client list"
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
 //
-// @Router       /services/clients [get]
+// @Success      200 {object} policyHistoryResponse "List of policy revisions"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter"
+// @Failure      500 {object} appErr.HttpError "Internal server error while reading the history"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/policy/history [get]
 //
 // @Example Request:
-// GET /services/clients
-func (sh *ServiceHandler) ServeClients(c *gin.Context) {
-	clients := sh.Client.GetAll()
-	c.JSON(http.StatusOK, clients)
+// GET /services/casb/policy/history
+func (sh *ServiceHandler) ServePolicyHistory(c *gin.Context) {
+	service := c.Param("service")
+
+	revs, err := sh.History[service].List()
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read policy history", zap.Error(err), zap.String("service", service))
+		return
+	}
+	if revs == nil {
+		revs = []policy.Revision{}
+	}
+
+	c.JSON(http.StatusOK, &policyHistoryResponse{
+		Service:   service,
+		Revisions: revs,
+	})
 }
 
-// ServeServiceClients godoc
-// @Summary      Get clients by service
-// @Description  Returns a list of registered OPA client addresses for the specified service.
+// ServePolicyDiff godoc
+// @Summary      Diff two policy revisions
+// @Description  Returns a unified diff between two revisions of the service's policy set.
+// @Description  If `to` is omitted, the latest revision is used.
+//
 // @Tags         service
-// @Produce      json
+// @Produce      plain
 //
-// @Param        service path string true "Service name <br> Only services listed in clients.service of the config file are allowed."
-// @Success      200 {array} clientGroup "List of registered clients"
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        from query int true "Base revision"
+// @Param        to query int false "Target revision (default: latest)"
 //
-// @Failure      400 {object} appErr.HttpError "Invalid service parameters"
+// @Success      200 {string} string "Unified diff (empty if the revisions are identical)"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter or revision"
+// @Failure      404 {object} appErr.HttpError "Revision not found"
+// @Failure      500 {object} appErr.HttpError "Internal server error while reading the revisions"
 //
-// @Router       /services/{service}/clients [get]
+// @Security     BearerAuth
+// @Router       /services/{service}/policy/diff [get]
 //
 // @Example Request:
-// GET /services/casb/clients
-func (sh *ServiceHandler) ServeServiceClients(c *gin.Context) {
+// GET /services/casb/policy/diff?from=3&to=5
+func (sh *ServiceHandler) ServePolicyDiff(c *gin.Context) {
 	service := c.Param("service")
+	history := sh.History[service]
+
+	from, ok := sh.revisionParam(c, service, "from")
+	if !ok {
+		return
+	}
+
+	var to int64
+	if c.Query("to") != "" {
+		if to, ok = sh.revisionParam(c, service, "to"); !ok {
+			return
+		}
+	} else {
+		revs, err := history.List()
+		if err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to read policy history", zap.Error(err), zap.String("service", service))
+			return
+		}
+		if len(revs) > 0 {
+			to = revs[len(revs)-1].Revision
+		}
+	}
+
+	snapshots := make([]map[string][]byte, 2)
+	for i, rev := range []int64{from, to} {
+		snapshot, err := history.Snapshot(rev)
+		if err != nil {
+			sh.handleRevisionError(c, service, err)
+			return
+		}
+		snapshots[i] = snapshot
+	}
 
-	clients := sh.Client.Get(service)
-	c.JSON(http.StatusOK, clients)
+	diff := policy.UnifiedDiff(snapshots[0], snapshots[1], fmt.Sprintf("rev-%d", from), fmt.Sprintf("rev-%d", to))
+	c.Data(http.StatusOK, "text/x-diff; charset=utf-8", []byte(diff))
 }
 
-// DeleteClients godoc
-// @Summary      Delete one or all OPA clients for a service
-// @Description  Deletes a specific client (by IP or DNS) or all clients for a service if no client is specified.
+// RestorePolicy godoc
+// @Summary      Restore a policy revision
+// @Description  Replaces the service's current policy set with the snapshot of the given revision.
+// @Description  The restored set is validated like an upload and recorded as a new revision.
+// @Description  The bundle is not rebuilt; call `/services/{service}/policy/trigger` to publish it.
+//
 // @Tags         service
 // @Produce      json
 //
-// @Param        service path string true "Service name <br> Only services listed in clients.service of the config file are allowed."
-// @Param        client query string false "Client address (IP or domain). If omitted, all clients will be deleted."
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        rev query int true "Revision to restore"
 //
-// @Success      200 {object} httpResponse "Client(s) deleted successfully"
-// @Failure      400 {object} appErr.HttpError "Invalid service parameters"
-// @Failure      404 {object} httpResponse "Client not found"
+// @Success      200 {object} httpResponse "OK - The revision was restored"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter or revision"
+// @Failure      404 {object} appErr.HttpError "Revision not found"
+// @Failure      422 {object} policyErrorResponse "The restored policy set fails to compile with the current data.json"
+// @Failure      500 {object} appErr.HttpError "Internal server error while restoring the revision"
 //
-// @Router       /services/{service}/clients [delete]
+// @Security     BearerAuth
+// @Router       /services/{service}/policy/restore [post]
 //
 // @Example Request:
-// DELETE /services/casb/clients?client=http://127.0.0.1:5556
-// DELETE /services/casb/clients
-func (sh *ServiceHandler) DeleteClients(c *gin.Context) {
-	t := c.Query("client")
+// POST /services/casb/policy/restore?rev=3
+func (sh *ServiceHandler) RestorePolicy(c *gin.Context) {
 	service := c.Param("service")
 
-	if t != "" {
-		err := sh.Client.Delete(service, t)
-		if err != nil {
+	rev, ok := sh.revisionParam(c, service, "rev")
+	if !ok {
+		return
+	}
+
+	snapshot, err := sh.History[service].Snapshot(rev)
+	if err != nil {
+		sh.handleRevisionError(c, service, err)
+		return
+	}
+
+	current, currentTests, err := loadPolicySet(service)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read policy modules", zap.Error(err), zap.String("service", service))
+		return
+	}
+	if !sh.initPolicyHistory(c, service, current, currentTests) {
+		return
+	}
+
+	modules, tests := map[string][]byte{}, map[string][]byte{}
+	for name, src := range snapshot {
+		if policy.IsTestFile(name) {
+			tests[name] = src
+		} else {
+			modules[name] = src
+		}
+	}
+	if !sh.validatePolicy(c, service, modules, tests) {
+		return
+	}
+
+	var changed []string
+	for _, set := range []map[string][]byte{current, currentTests} {
+		for name := range set {
+			if _, ok := snapshot[name]; ok {
+				continue
+			}
+			if err := os.Remove(policyFilePath(service, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				appErr.HandleError(c, sh.Logger, appErr.HttpError{
+					Code:   "internal_server_error",
+					Status: http.StatusInternalServerError,
+					Err:    err.Error(),
+				}, "failed to delete policy module", zap.Error(err), zap.String("service", service), zap.String("path", name))
+				return
+			}
+			changed = append(changed, name)
+		}
+	}
+	for name, src := range snapshot {
+		old, ok := current[name]
+		if !ok {
+			old, ok = currentTests[name]
+		}
+		if ok && bytes.Equal(old, src) {
+			continue
+		}
+		err = utils.SaveToFileWithLock(c.Request.Context(), bytes.NewReader(src), policyFilePath(service, name))
+		if err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to save policy module", zap.Error(err), zap.String("service", service), zap.String("path", name))
+			return
+		}
+		changed = append(changed, name)
+	}
+	sh.Info("policy revision restored successfully", zap.String("service", service), zap.Int64("revision", rev))
+
+	if !sh.recordPolicyHistory(c, service, policy.Revision{
+		Action:       policy.ActionRestore,
+		Files:        changed,
+		RestoredFrom: rev,
+	}, modules, tests) {
+		return
+	}
+
+	c.JSON(http.StatusOK, &httpResponse{
+		Code:    "success",
+		Message: fmt.Sprintf("revision %d was restored successfully.", rev),
+		Status:  http.StatusOK,
+	})
+}
+
+// 이력이 없는 경우 변경 전 정책을 첫 revision으로 기록
+func (sh *ServiceHandler) initPolicyHistory(c *gin.Context, service string, modules, tests map[string][]byte) bool {
+	err := sh.History[service].Init(c.Request.Context(), mergePolicySet(modules, tests))
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to record policy history", zap.Error(err), zap.String("service", service))
+		return false
+	}
+	return true
+}
+
+// 변경 후 정책을 새 revision으로 기록
+func (sh *ServiceHandler) recordPolicyHistory(c *gin.Context, service string, rev policy.Revision, modules, tests map[string][]byte) bool {
+	rev.Author = policyAuthor(c)
+
+	recorded, err := sh.History[service].Record(c.Request.Context(), rev, mergePolicySet(modules, tests))
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to record policy history", zap.Error(err), zap.String("service", service))
+		return false
+	}
+
+	sh.Info("policy revision recorded",
+		zap.String("service", service),
+		zap.Int64("revision", recorded.Revision),
+		zap.String("action", recorded.Action),
+		zap.String("author", recorded.Author),
+	)
+	return true
+}
+
+func (sh *ServiceHandler) revisionParam(c *gin.Context, service, key string) (int64, bool) {
+	rev, err := strconv.ParseInt(c.Query(key), 10, 64)
+	if err != nil || rev <= 0 {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    fmt.Sprintf("invalid revision in query '%s': %q", key, c.Query(key)),
+		}, "invalid revision", zap.String("service", service), zap.String(key, c.Query(key)))
+		return 0, false
+	}
+	return rev, true
+}
+
+func (sh *ServiceHandler) handleRevisionError(c *gin.Context, service string, err error) {
+	status, code := http.StatusInternalServerError, "internal_server_error"
+	if errors.Is(err, policy.ErrRevisionNotFound) {
+		status, code = http.StatusNotFound, "not_found"
+	}
+	appErr.HandleError(c, sh.Logger, appErr.HttpError{
+		Code:   code,
+		Status: status,
+		Err:    err.Error(),
+	}, "failed to read policy revision", zap.Error(err), zap.String("service", service))
+}
+
+// 변경 요청자 (인증된 사용자, X-User 헤더, client IP 순)
+func policyAuthor(c *gin.Context) string {
+	if v, ok := c.Get(contextkey.Identity); ok {
+		if id, ok := v.(*auth.Identity); ok {
+			return id.Subject
+		}
+	}
+	if user := c.GetHeader("X-User"); user != "" {
+		return user
+	}
+	return c.ClientIP()
+}
+
+// module과 test 파일은 파일명(*_test.rego)으로 구분되므로 하나의 snapshot으로 병합
+func mergePolicySet(modules, tests map[string][]byte) map[string][]byte {
+	files := make(map[string][]byte, len(modules)+len(tests))
+	for name, src := range modules {
+		files[name] = src
+	}
+	for name, src := range tests {
+		files[name] = src
+	}
+	return files
+}
+
+func (sh *ServiceHandler) policyPathParam(c *gin.Context, service string) (string, bool) {
+	name, err := policy.CleanPath(strings.TrimPrefix(c.Param("path"), "/"))
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "invalid policy path", zap.Error(err), zap.String("service", service))
+		return "", false
+	}
+	return name, true
+}
+
+// form file => 정책 파일 (상대경로 => 내용)
+// paths[i]가 있는 경우 i번째 파일의 경로로 사용, tar.gz 파일은 내부 경로 유지
+func readPolicyUploads(headers []*multipart.FileHeader, paths []string) (map[string][]byte, error) {
+	uploads := map[string][]byte{}
+	add := func(name string, src []byte) error {
+		name, err := policy.CleanPath(name)
+		if err != nil {
+			return err
+		}
+		uploads[name] = src
+		return nil
+	}
+
+	for i, fh := range headers {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open form file: %w", err)
+		}
+		src, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read form file: %w", err)
+		}
+
+		if strings.HasSuffix(fh.Filename, ".tar.gz") || strings.HasSuffix(fh.Filename, ".tgz") {
+			files, err := bundle.ReadTarGz(bytes.NewReader(src))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+			}
+			for _, file := range files {
+				if err := add(file.Name, file.Data); err != nil {
+					return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+				}
+			}
+			continue
+		}
+
+		name := fh.Filename
+		if i < len(paths) && paths[i] != "" {
+			name = paths[i]
+		}
+		if err := add(name, src); err != nil {
+			return nil, err
+		}
+	}
+
+	return uploads, nil
+}
+
+// <opa_data_path>/<service>/regular 의 module과 <opa_data_path>/<service>/tests 의 test 파일
+func loadPolicySet(service string) (map[string][]byte, map[string][]byte, error) {
+	modules, err := policy.ReadModules(fmt.Sprintf("%s/%s/regular", config.Cfg.OpaDataPath, service))
+	if err != nil {
+		return nil, nil, err
+	}
+	tests, err := loadPolicyTests(service)
+	if err != nil {
+		return nil, nil, err
+	}
+	return modules, tests, nil
+}
+
+// *_test.rego는 bundle에 포함되지 않도록 tests 디렉토리에 저장
+func policyFilePath(service, name string) string {
+	dir := "regular"
+	if policy.IsTestFile(name) {
+		dir = "tests"
+	}
+	return filepath.Join(config.Cfg.OpaDataPath, service, dir, filepath.FromSlash(name))
+}
+
+func sortedNames(m map[string][]byte) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rego 검증 실패 시 422 응답 후 false 반환
+// test 파일은 roots 검사 없이 module과 함께 컴파일만 확인
+func (sh *ServiceHandler) validatePolicy(c *gin.Context, service string, modules, tests map[string][]byte) bool {
+	data, err := readDataJson(fmt.Sprintf("%s/%s/regular/data.json", config.Cfg.OpaDataPath, service))
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read data.json", zap.Error(err), zap.String("service", service))
+		return false
+	}
+
+	err = policy.Validate(modules, data, sh.Client.Bundle[service].Roots)
+	if err == nil && len(tests) > 0 {
+		all := make(map[string][]byte, len(modules)+len(tests))
+		for name, src := range modules {
+			all[name] = src
+		}
+		for name, src := range tests {
+			all[path.Join("tests", name)] = src
+		}
+		err = policy.Validate(all, nil, nil)
+	}
+	if err == nil {
+		return true
+	}
+
+	if !sh.handlePolicyError(c, service, err) {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to validate policy", zap.Error(err), zap.String("service", service))
+	}
+	return false
+}
+
+// service의 모든 client에 webhook 전송 후 결과 로깅 (실패한 전송은 outbox에서 재시도)
+// SSE 구독자에게 먼저 발행
+func (sh *ServiceHandler) notifyClients(path string, event clients.HookEvent) {
+	if sh.Events != nil {
+		sh.Events.Publish(events.Event{
+			Service:    event.Service,
+			BundleType: event.Type,
+			Version:    event.Version,
+			ETag:       event.ETag,
+			Timestamp:  event.Timestamp,
+		})
+	}
+
+	results, err := sh.Client.Hook(context.Background(), sh.Logger, path, event)
+	if err != nil {
+		sh.Error("failed to event notification", zap.Error(err), zap.String("service", event.Service))
+	}
+
+	delivered := 0
+	for _, r := range results {
+		if r.Status == clients.DeliveryDelivered {
+			delivered++
+		}
+	}
+	sh.Info("event notification sent",
+		zap.String("service", event.Service),
+		zap.String("version", event.Version),
+		zap.Int("clients", len(results)),
+		zap.Int("delivered", delivered),
+	)
+}
+
+// rego 컴파일 오류 또는 policy test 실패인 경우 422 응답 후 true 반환
+func (sh *ServiceHandler) handlePolicyError(c *gin.Context, service string, err error) bool {
+	var (
+		verr *policy.ValidationError
+		terr *policy.TestError
+		resp *policyErrorResponse
+	)
+
+	switch {
+	case errors.As(err, &verr):
+		resp = &policyErrorResponse{
+			Code:        "invalid_policy",
+			Err:         "policy compilation failed",
+			Diagnostics: verr.Diagnostics,
+		}
+	case errors.As(err, &terr):
+		resp = &policyErrorResponse{
+			Code:     "policy_test_failed",
+			Err:      terr.Error(),
+			Failures: terr.Failures,
+		}
+	default:
+		return false
+	}
+
+	sh.Info("policy check failed", zap.String("service", service), zap.Error(err))
+	resp.Status = http.StatusUnprocessableEntity
+	c.Set(contextkey.LogLevel, zap.InfoLevel)
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, resp)
+	return true
+}
+
+// <opa_data_path>/<service>/tests/**/*_test.rego (bundle에는 포함되지 않음)
+func loadPolicyTests(service string) (map[string][]byte, error) {
+	files, err := policy.ReadModules(fmt.Sprintf("%s/%s/tests", config.Cfg.OpaDataPath, service))
+	if err != nil {
+		return nil, err
+	}
+
+	tests := map[string][]byte{}
+	for name, data := range files {
+		if policy.IsTestFile(name) {
+			tests[name] = data
+		}
+	}
+	return tests, nil
+}
+
+// data.json => map (없는 경우 nil)
+func readDataJson(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode data.json: %w", err)
+	}
+	return data, nil
+}
+
+// ServeBundle godoc
+// @Summary      Download OPA bundle file
+// @Description  Downloads an OPA bundle file (.tar.gz) for the specified service.
+// @Description  By default, the latest **regular** bundle is served.
+// @Description  To download a **delta** bundle, use the query `?type=delta`.
+// @Description  With `?type=delta&from=X.Y`, the deltas from version X.Y up to the latest version are composed into a single delta bundle.
+// @Description  If the delta chain from X.Y is broken, the latest regular bundle is served instead.
+// @Description  To request a specific version of the regular bundle, use the query `?version=X.Y`.
+// @Description  Supports ETag validation using the `If-None-Match` header.
+// @Description  If authentication is enabled, a token with the `reader` role for the service is required (OPA `credentials.bearer`).
+// @Description  Supports OPA long polling: with `Prefer: wait=N` and a matching ETag, the request is held until a new bundle is published or N seconds pass (capped by `bundle.long_poll_max_wait`).
+//
+// @Tags         service
+// @Produce      application/gzip
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        Prefer header string false "OPA preferences (e.g. modes=snapshot,delta;wait=30)"
+// @Param        type query string false "Bundle type: 'regular' (default) or 'delta'"
+// @Param        version query string false "Regular bundle version in format 'X.Y' (e.g., 1.2)"
+// @Param        from query string false "Delta base version in format 'X.Y' (only with type=delta)"
+//
+// @Success      200 {file} file "The requested bundle file (.tar.gz)"
+// @Success      304 {object} httpResponse "Not Modified - Client already has the latest bundle"
+// @Failure      400 {object} appErr.HttpError "Invalid service or version parameter, or file not found"
+// @Failure      401 {object} appErr.HttpError "Missing or invalid bearer token"
+// @Failure      403 {object} appErr.HttpError "Token has no reader role for this service"
+// @Failure      404 {object} appErr.HttpError "Requested version is not recorded in the bundle index"
+// @Failure      500 {object} appErr.HttpError "Internal server error while serving the bundle"
+//
+// @Header       200 {string} ETag "ETag header containing current bundle hash"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/bundle [get]
+//
+// @Example Request:
+// GET /services/casb/bundle
+// GET /services/casb/bundle?type=delta
+// GET /services/casb/bundle?type=delta&from=1.2
+// GET /services/casb/bundle?version=1.3
+func (sh *ServiceHandler) ServeBundle(c *gin.Context) {
+	var path string
+	var filename string
+	var etag string
+
+	service := c.Param("service")
+	version := c.Query("version")
+	t := c.Query("type")
+
+	switch t {
+	case "delta":
+		sh.serveDeltaBundle(c, service)
+		return
+	case "", "regular": // type이 비어있거나 regular인 경우 => regular-bundle 리턴
+
+		// If-Non-Match 헤더와 비교
+		etag = sh.Client.Bundle[service].GetEtag()
+		clientEtag := c.GetHeader("If-None-Match")
+
+		// long polling: 새 bundle이 배포되거나 wait가 지날 때까지 대기
+		if etag == clientEtag && version == "" && sh.waitForBundle(c, service, etag) {
+			etag = sh.Client.Bundle[service].GetEtag()
+		}
+
+		sh.Debug("etag", zap.String("etag", etag), zap.String("clientEtag", clientEtag))
+		if etag == clientEtag {
+			notModified(c, "To request the latest bundle, please omit the version query parameter.")
+			return
+		}
+
+		b := sh.Client.Bundle[service]
+		major := b.Latest.GetMajor()
+		minor := b.Latest.GetMinor()
+
+		var vMajor, vMinor int
+		if version != "" {
+			var err error
+			if vMajor, vMinor, err = bundle.ParseVersion(version); err != nil {
+				appErr.HandleError(c, sh.Logger, appErr.HttpError{
+					Code:   "bad_request",
+					Status: http.StatusBadRequest,
+					Err:    err.Error(),
+				}, "invalid version", zap.Error(err), zap.String("service", service))
+				return
+			}
+		}
+
+		// version이 비어있는경우 또는 latest 를 요청하는 경우
+		if version == "" || (vMajor == major && vMinor == minor) {
+			path = filepath.Join(b.DirPath, bundle.RegularFileName(major, minor))
+			filename = fmt.Sprintf("%s_%s", service, bundle.RegularFileName(major, minor))
+			c.Header("ETag", etag) // 최신번들 요청일 경우에만 삽입
+		} else {
+			// index에 기록된 버전만 제공 (query 값을 파일 경로에 그대로 사용하지 않음)
+			entry, err := b.Entry(bundle.Revision(vMajor, vMinor), bundle.TypeRegular, "")
+			if err != nil {
+				appErr.HandleError(c, sh.Logger, appErr.HttpError{
+					Code:   "not_found",
+					Status: http.StatusNotFound,
+					Err:    err.Error(),
+				}, "bundle version not found", zap.Error(err), zap.String("service", service))
+				return
+			}
+			path = filepath.Join(b.DirPath, entry.File)
+			filename = fmt.Sprintf("%s_%s", service, entry.File)
+		}
+	default:
+		sh.Info("Invalid bundle type, defaulting to regular bundle",
+			zap.String("requested_type", t),
+			zap.String("service", service),
+		)
+		major := sh.Client.Bundle[service].Latest.GetMajor()
+		minor := sh.Client.Bundle[service].Latest.GetMinor()
+		path = fmt.Sprintf("%s/%s/regular-v%d.%d.tar.gz", config.Cfg.OpaDataPath, service, major, minor)
+		filename = fmt.Sprintf("%s_regular-v%d.%d.tar.gz", service, major, minor)
+	}
+
+	sh.serveBundleFile(c, service, path, filename)
+}
+
+// from 버전부터 Latest까지의 delta를 전달
+// delta가 하나인 경우 파일 그대로, 여러 개인 경우 patch를 순서대로 합친 delta bundle을 생성
+// chain이 끊긴 경우 최신 regular bundle로 대체
+func (sh *ServiceHandler) serveDeltaBundle(c *gin.Context, service string) {
+	b := sh.Client.Bundle[service]
+	major, minor := b.Latest.GetMajor(), b.Latest.GetMinor()
+
+	var (
+		chain []string
+		err   error
+	)
+
+	from := c.Query("from")
+	if from == "" {
+		var path string
+		path, err = b.LatestDelta()
+		chain = []string{path}
+	} else {
+		fMajor, fMinor, perr := bundle.ParseVersion(from)
+		if perr != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "bad_request",
+				Status: http.StatusBadRequest,
+				Err:    perr.Error(),
+			}, "invalid from version", zap.Error(perr), zap.String("service", service))
+			return
+		}
+
+		if fMajor == major && fMinor == minor && sh.waitForBundle(c, service, b.GetEtag()) {
+			major, minor = b.Latest.GetMajor(), b.Latest.GetMinor()
+		}
+
+		if fMajor == major && fMinor == minor {
+			notModified(c, "Client already has the latest bundle.")
+			return
+		}
+
+		chain, err = b.DeltaChain(fMajor, fMinor)
+	}
+
+	if err != nil {
+		sh.Info("delta chain is not available, serving regular bundle instead",
+			zap.String("service", service),
+			zap.String("from", from),
+			zap.Error(err),
+		)
+		c.Header("ETag", b.GetEtag())
+		sh.serveBundleFile(c, service,
+			filepath.Join(b.DirPath, bundle.RegularFileName(major, minor)),
+			fmt.Sprintf("%s_%s", service, bundle.RegularFileName(major, minor)),
+		)
+		return
+	}
+
+	if len(chain) == 1 {
+		sh.serveBundleFile(c, service, chain[0], fmt.Sprintf("%s_%s", service, filepath.Base(chain[0])))
+		return
+	}
+
+	fMajor, fMinor, _ := bundle.ParseVersion(from)
+	filename := fmt.Sprintf("%s_%s", service, bundle.DeltaFileName(fMajor, fMinor, major, minor))
+
+	buf, err := composeDeltaBundle(chain, b.NewManifest(bundle.Revision(major, minor), bundle.TypeDelta), b.Signer)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to compose delta bundle", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	sh.Info("serve composed delta bundle", zap.String("name", filename), zap.Int("deltas", len(chain)))
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=%s", filename))
+	c.Data(http.StatusOK, bundleContentType(c, filename), buf.Bytes())
+}
+
+// OPA는 bundle 응답(304 포함)의 Content-Type이 opaBundleMediaType인 경우에만 long polling을 유지
+func bundleContentType(c *gin.Context, filename string) string {
+	if preferWait(c.GetHeader("Prefer")) > 0 && config.Cfg.Bundle.LongPollMaxWait > 0 {
+		return opaBundleMediaType
+	}
+	return mime.TypeByExtension(filepath.Ext(filename))
+}
+
+func notModified(c *gin.Context, message string) {
+	c.Set(contextkey.LogLevel, zap.InfoLevel)
+	if ct := bundleContentType(c, ""); ct != "" {
+		c.Header("Content-Type", ct) // c.JSON은 Content-Type이 비어있는 경우에만 설정
+	}
+	c.JSON(http.StatusNotModified, &httpResponse{
+		Code:    "not_modified",
+		Message: message,
+		Status:  http.StatusNotModified,
+	})
+}
+
+func (sh *ServiceHandler) serveBundleFile(c *gin.Context, service, path, filename string) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "bundle file not found", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	sh.Info("serve bundle", zap.String("name", filename))
+
+	c.Header("Content-Type", bundleContentType(c, filename))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=%s", filename))
+	http.ServeFile(c.Writer, c.Request, path)
+}
+
+// Prefer: wait=N 요청인 경우 bundle의 etag가 바뀔 때까지 최대 N초(long_poll_max_wait 이하) 대기
+// 새 bundle이 배포되면 true
+func (sh *ServiceHandler) waitForBundle(c *gin.Context, service, etag string) bool {
+	wait := preferWait(c.GetHeader("Prefer"))
+	if limit := time.Duration(config.Cfg.Bundle.LongPollMaxWait) * time.Second; wait > limit {
+		wait = limit
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	// TimeOutMiddleware의 timeout보다 길게 대기할 수 있도록 원래 요청 context 사용
+	parent, stop := longLivedContext(c)
+	defer stop()
+	ctx, cancel := context.WithTimeout(parent, wait)
+	defer cancel()
+
+	sh.Debug("long polling", zap.String("service", service), zap.Duration("wait", wait))
+	return sh.Client.Bundle[service].WaitForChange(ctx, etag)
+}
+
+// TimeOutMiddleware 적용 전의 요청 context (long polling, SSE 등 오래 유지되는 요청용)
+func requestContext(c *gin.Context) context.Context {
+	if v, ok := c.Get(contextkey.RequestContext); ok {
+		if ctx, ok := v.(context.Context); ok {
+			return ctx
+		}
+	}
+	return c.Request.Context()
+}
+
+// requestContext에 서버 종료 시 취소를 추가한 context
+// http.Server.Shutdown은 진행 중인 요청이 끝나기를 기다리므로 오래 유지되는 요청은 종료 시 직접 끝내야 함
+func longLivedContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(requestContext(c))
+	shutdown, ok := c.Request.Context().Value(contextkey.ShutdownKey{}).(context.Context)
+	if !ok {
+		return ctx, cancel
+	}
+	stop := context.AfterFunc(shutdown, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// "modes=snapshot,delta;wait=30" => 30s
+func preferWait(prefer string) time.Duration {
+	for _, pref := range strings.FieldsFunc(prefer, func(r rune) bool { return r == ';' || r == ',' }) {
+		k, v, ok := strings.Cut(strings.TrimSpace(pref), "=")
+		if !ok || !strings.EqualFold(k, "wait") {
+			continue
+		}
+		if sec, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && sec > 0 {
+			return time.Duration(sec) * time.Second
+		}
+	}
+	return 0
+}
+
+// RollbackBundle godoc
+// @Summary      Roll back to an older regular bundle version
+// @Description  Makes an existing regular bundle version the served latest bundle and recomputes the ETag.
+// @Description  The data.json of that version is restored as the baseline for the next delta bundle.
+// @Description  Sends webhook POST /hooks/bundle-update to notify OPA SDK clients.
+//
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        version query string true "Regular bundle version in format 'X.Y' (e.g., 1.2)"
+//
+// @Success      202 {object} httpResponse "Accepted - Rolled back and notification will be sent to OPA clients"
+// @Failure      400 {object} appErr.HttpError "Invalid service or version parameter"
+// @Failure      404 {object} appErr.HttpError "Bundle version not found"
+// @Failure      500 {object} appErr.HttpError "Internal server error during rollback"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/bundle/rollback [post]
+//
+// @Example Request:
+// POST /services/casb/bundle/rollback?version=1.2
+func (sh *ServiceHandler) RollbackBundle(c *gin.Context) {
+	service := c.Param("service")
+	dataPath := fmt.Sprintf("%s/%s/regular/data.json", config.Cfg.OpaDataPath, service)
+
+	major, minor, err := bundle.ParseVersion(c.Query("version"))
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "invalid version", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	b := sh.Client.Bundle[service]
+
+	// trigger가 rollback 도중의 data.json을 기준으로 delta를 만들지 않도록 게시를 직렬화
+	unlock := b.LockPublish()
+	defer unlock()
+
+	// 다음 delta의 기준이 될 data.json
+	data, err := readBundleData(filepath.Join(b.DirPath, bundle.RegularFileName(major, minor)))
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_server_error"
+		if errors.Is(err, os.ErrNotExist) {
+			status, code = http.StatusNotFound, "not_found"
+		}
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   code,
+			Status: status,
+			Err:    err.Error(),
+		}, "failed to read bundle", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	// data.json을 먼저 복원 (Latest 변경 후 복원에 실패하면 다음 trigger가 잘못된 data.json과 비교)
+	prev, err := os.ReadFile(dataPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read data.json", zap.Error(err), zap.String("service", service))
+		return
+	}
+	if data != nil {
+		err = utils.SaveToFileWithLock(c.Request.Context(), bytes.NewReader(data), dataPath)
+		if err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to restore data.json", zap.Error(err), zap.String("service", service))
+			return
+		}
+	} else {
+		sh.Info("No data.json in bundle. Skipping data.json restore", zap.String("service", service), zap.String("version", bundle.Revision(major, minor)))
+	}
+
+	entry, err := b.Rollback(c.Request.Context(), major, minor)
+	if err != nil {
+		// Latest가 바뀌지 않았으므로 data.json도 되돌림
+		if data != nil {
+			var rerr error
+			if prev != nil {
+				rerr = utils.SaveToFileWithLock(context.WithoutCancel(c.Request.Context()), bytes.NewReader(prev), dataPath)
+			} else {
+				rerr = os.Remove(dataPath)
+			}
+			if rerr != nil {
+				sh.Error("failed to revert data.json", zap.Error(rerr), zap.String("service", service))
+			}
+		}
+		status, code := http.StatusInternalServerError, "internal_server_error"
+		if errors.Is(err, bundle.ErrVersionNotFound) {
+			status, code = http.StatusNotFound, "not_found"
+		}
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   code,
+			Status: status,
+			Err:    err.Error(),
+		}, "failed to roll back bundle", zap.Error(err), zap.String("service", service))
+		return
+	}
+	sh.Info("Bundle rolled back", zap.String("service", service), zap.String("version", entry.Version))
+
+	go func(major, minor int) {
+		event := clients.NewHookEvent(service, bundle.TypeRegular, major, minor, b.GetEtag(), time.Now())
+		sh.notifyClients(clients.DefaultHookPath, event)
+	}(major, minor)
+
+	c.JSON(http.StatusAccepted, &httpResponse{
+		Code:    "success",
+		Message: fmt.Sprintf("Rolled back to %s. Notification will be sent to the OPA client.", entry.Version),
+		Status:  http.StatusAccepted,
+	})
+}
+
+// ListBundles godoc
+// @Summary      List all bundle versions of a service
+// @Description  Returns every regular and delta bundle recorded for the service with its size, sha256/ETag, creation time and type.
+// @Description  The bundle currently served as latest is marked with `latest: true`.
+//
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+//
+// @Success      200 {object} bundleListResponse "Bundles ordered by creation time"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameter"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/bundles [get]
+//
+// @Example Request:
+// GET /services/casb/bundles
+func (sh *ServiceHandler) ListBundles(c *gin.Context) {
+	service := c.Param("service")
+	b := sh.Client.Bundle[service]
+	latest := b.LatestVersion()
+
+	entries := b.Entries()
+	bundles := make([]bundleInfo, 0, len(entries))
+	for _, e := range entries {
+		bundles = append(bundles, newBundleInfo(e, latest))
+	}
+
+	c.JSON(http.StatusOK, &bundleListResponse{
+		Service: service,
+		Latest:  latest,
+		Bundles: bundles,
+	})
+}
+
+// ServeBundleInfo godoc
+// @Summary      Show the manifest and file listing of a bundle
+// @Description  Returns the metadata, `.manifest` and file listing of a bundle without downloading it.
+// @Description  By default, the regular bundle of the version is described.
+// @Description  With `?type=delta`, the delta bundle ending at the version is described (optionally narrowed down by `from`).
+//
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        version path string true "Bundle version in format 'X.Y' (e.g., 1.2)"
+// @Param        type query string false "Bundle type: 'regular' (default) or 'delta'"
+// @Param        from query string false "Delta base version in format 'X.Y' (only with type=delta)"
+//
+// @Success      200 {object} bundleDetailResponse "Bundle metadata, manifest and files"
+// @Failure      400 {object} appErr.HttpError "Invalid service or version parameter"
+// @Failure      404 {object} appErr.HttpError "Bundle version not found"
+// @Failure      500 {object} appErr.HttpError "Internal server error while reading the bundle"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/bundles/{version} [get]
+//
+// @Example Request:
+// GET /services/casb/bundles/1.2
+// GET /services/casb/bundles/1.2?type=delta&from=1.1
+func (sh *ServiceHandler) ServeBundleInfo(c *gin.Context) {
+	service := c.Param("service")
+	b := sh.Client.Bundle[service]
+
+	var from string
+	version, err := parseVersionParam(c.Param("version"))
+	if err == nil && c.Query("from") != "" {
+		from, err = parseVersionParam(c.Query("from"))
+	}
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "invalid version", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	t := c.DefaultQuery("type", bundle.TypeRegular)
+	if t != bundle.TypeRegular && t != bundle.TypeDelta {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    "Invalid bundle type",
+		}, "invalid bundle type", zap.String("type", t), zap.String("service", service))
+		return
+	}
+
+	entry, err := b.Entry(version, t, from)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "not_found",
+			Status: http.StatusNotFound,
+			Err:    err.Error(),
+		}, "bundle not found", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	manifest, files, err := bundle.Inspect(filepath.Join(b.DirPath, entry.File))
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_server_error"
+		if errors.Is(err, os.ErrNotExist) {
+			status, code = http.StatusNotFound, "not_found"
+		}
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   code,
+			Status: status,
+			Err:    err.Error(),
+		}, "failed to read bundle", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	c.JSON(http.StatusOK, &bundleDetailResponse{
+		bundleInfo: newBundleInfo(*entry, b.LatestVersion()),
+		Manifest:   manifest,
+		Files:      files,
+	})
+}
+
+func newBundleInfo(e bundle.IndexEntry, latest string) bundleInfo {
+	return bundleInfo{
+		Version:   e.Version,
+		Revision:  e.Revision,
+		Type:      e.Type,
+		From:      e.From,
+		File:      e.File,
+		Size:      e.Size,
+		SHA256:    e.SHA256,
+		ETag:      `"` + e.SHA256 + `"`,
+		CreatedAt: e.CreatedAt,
+		Trigger:   e.Trigger,
+		Latest:    e.Type == bundle.TypeRegular && e.Version == latest,
+	}
+}
+
+// "X.Y" 또는 "vX.Y" => "vX.Y"
+func parseVersionParam(s string) (string, error) {
+	major, minor, err := bundle.ParseVersion(s)
+	if err != nil {
+		return "", err
+	}
+	return bundle.Revision(major, minor), nil
+}
+
+// bundle 내 data.json (없는 경우 nil)
+func readBundleData(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files, err := bundle.ReadTarGz(f)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if filepath.Base(file.Name) == "data.json" {
+			return file.Data, nil
+		}
+	}
+	return nil, nil
+}
+
+// RegisterClients godoc
+// @Summary      Register OPA webhook clients
+// @Description  Registers one or more OPA SDK clients for the specified service.
+// @Description  Each item is either a client URL or an object with `url` and optional `id`, `labels`, `hook_path` (default `hooks/bundle-update`), `hook_method` (POST or PUT), `health_path` and `secret`.
+// @Description  The `id` is generated when omitted. The `secret` signs webhooks with `X-Signature` and is never returned.
+// @Description  These clients will be notified via webhook when a new bundle is available.
+//
+// @Tags         service
+// @Accept       json
+// @Produce      json
+//
+// @Param        service path string true "Service name (must be listed in config.clients.service)"
+// @Param        clients body []clientRequest true "Clients to register (objects or URL strings)"
+//
+// @Success      200 {array}  clients.Record "Registered clients"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameters, JSON format, client fields or no clients provided"
+// @Failure      409 {object} appErr.HttpError "Conflict - one or more client IDs or URLs already registered"
+// @Failure      500 {object} appErr.HttpError "Failed to update the client registry"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/clients [post]
+//
+// @Example Request:
+// POST /services/casb/clients
+// Content-Type: application/json
+// [
+//
+//	"http://127.0.0.1:5556",
+//	{"id": "opa-kr-1", "url": "http://opa-client.k8s.local:8181", "labels": {"env": "prod", "region": "kr"}, "hook_path": "v1/hooks", "secret": "..."}
+//
+// ]
+func (sh *ServiceHandler) RegisterClients(c *gin.Context) {
+	service := c.Param("service")
+
+	var reqs []clientRequest
+
+	err := c.ShouldBindJSON(&reqs)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "Invalid reqeust body. Please check the JSON format", zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	if len(reqs) == 0 {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    "no clients found",
+		}, "no clients found", zap.String("service", service))
+		return
+	}
+
+	records := make([]clients.Record, 0, len(reqs))
+	for _, req := range reqs {
+		records = append(records, req.record())
+	}
+
+	registered, err := sh.Client.AddHookClient(c.Request.Context(), records, service)
+	if err != nil {
+		status, code, msg := http.StatusInternalServerError, "internal_server_error", "failed to register clients"
+		switch {
+		case errors.Is(err, clients.ErrInvalidClient):
+			status, code, msg = http.StatusBadRequest, "bad_request", "invalid client"
+		case errors.Is(err, appErr.ErrAlreadyRegistered):
+			status, code, msg = http.StatusConflict, "conflict", "client already exists"
+		}
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   code,
+			Status: status,
+			Err:    err.Error(),
+		}, msg, zap.Error(err), zap.String("service", service))
+		return
+	}
+
+	ids := make([]string, 0, len(registered))
+	for i := range registered {
+		ids = append(ids, registered[i].ID)
+		registered[i] = registered[i].Redacted()
+	}
+	sh.Info("client address has been successfully registerd", zap.String("service", service), zap.Strings("clients", ids))
+
+	c.JSON(http.StatusOK, registered)
+}
+
+// ServeClients godoc
+// @Summary      Get all registered OPA clients
+// @Description  Returns all registered OPA clients grouped by service, with their labels, hook settings, last webhook results and health (`healthy`, `unhealthy` or `quarantined`).
+// @Description  Quarantined clients are skipped by webhooks until a health check succeeds again.
+// @Description  `selector` filters clients by label (e.g. `env=prod,region in (kr,jp),!legacy`).
+// @Tags         service
+// @Produce      json
+//
+// @Param        selector query string false "Label selector (=, !=, in, notin, key, !key)"
+//
+// @Success      200 {object} clientGroupResponse "Map of service name to client list"
+// @Failure      400 {object} appErr.HttpError "Invalid label selector"
+// @Failure      500 {object} appErr.HttpError "Failed to read the client registry"
+//
+// @Security     BearerAuth
+// @Router       /services/clients [get]
+//
+// @Example Request:
+// GET /services/clients?selector=env=prod
+func (sh *ServiceHandler) ServeClients(c *gin.Context) {
+	sel, ok := sh.labelSelector(c)
+	if !ok {
+		return
+	}
+
+	all, err := sh.Client.GetAll(c.Request.Context())
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read clients", zap.Error(err))
+		return
+	}
+
+	groups := clientGroupResponse{}
+	for service, records := range all {
+		groups[service] = redactClients(sh.Client.WithHealth(clients.Filter(records, sel)))
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// ServeServiceClients godoc
+// @Summary      Get clients by service
+// @Description  Returns the registered OPA clients of the specified service, with their labels, hook settings, last webhook results and health (`healthy`, `unhealthy` or `quarantined`).
+// @Description  `selector` filters clients by label (e.g. `env=prod,region in (kr,jp),!legacy`).
+// @Tags         service
+// @Produce      json
+//
+// @Param        service  path  string true  "Service name <br> Only services listed in clients.service of the config file are allowed."
+// @Param        selector query string false "Label selector (=, !=, in, notin, key, !key)"
+// @Success      200 {array} clientGroup "List of registered clients"
+//
+// @Failure      400 {object} appErr.HttpError "Invalid service parameters or label selector"
+// @Failure      500 {object} appErr.HttpError "Failed to read the client registry"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/clients [get]
+//
+// @Example Request:
+// GET /services/casb/clients?selector=region in (kr,jp)
+func (sh *ServiceHandler) ServeServiceClients(c *gin.Context) {
+	service := c.Param("service")
+
+	sel, ok := sh.labelSelector(c)
+	if !ok {
+		return
+	}
+
+	records, err := sh.Client.Get(c.Request.Context(), service)
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "internal_server_error",
+			Status: http.StatusInternalServerError,
+			Err:    err.Error(),
+		}, "failed to read clients", zap.Error(err), zap.String("service", service))
+		return
+	}
+	c.JSON(http.StatusOK, redactClients(sh.Client.WithHealth(clients.Filter(records, sel))))
+}
+
+// DeleteClients godoc
+// @Summary      Delete one or all OPA clients for a service
+// @Description  Deletes a specific client (by ID or URL) or all clients for a service if no client is specified.
+// @Tags         service
+// @Produce      json
+//
+// @Param        service path string true "Service name <br> Only services listed in clients.service of the config file are allowed."
+// @Param        client query string false "Client ID or URL. If omitted, all clients will be deleted."
+//
+// @Success      200 {object} httpResponse "Client(s) deleted successfully"
+// @Failure      400 {object} appErr.HttpError "Invalid service parameters"
+// @Failure      404 {object} httpResponse "Client not found"
+// @Failure      500 {object} appErr.HttpError "Failed to update the client registry"
+//
+// @Security     BearerAuth
+// @Router       /services/{service}/clients [delete]
+//
+// @Example Request:
+// DELETE /services/casb/clients?client=opa-kr-1
+// DELETE /services/casb/clients?client=http://127.0.0.1:5556
+// DELETE /services/casb/clients
+func (sh *ServiceHandler) DeleteClients(c *gin.Context) {
+	t := c.Query("client")
+	service := c.Param("service")
+
+	if t != "" {
+		err := sh.Client.Delete(c.Request.Context(), service, t)
+		if err != nil {
+			status, code := http.StatusInternalServerError, "internal_server_error"
+			if errors.Is(err, appErr.ErrClientNotFound) {
+				status, code = http.StatusNotFound, "not_found"
+			}
 			appErr.HandleError(c, sh.Logger, appErr.HttpError{
-				Code:   "not_found",
-				Status: http.StatusNotFound,
+				Code:   code,
+				Status: status,
 				Err:    err.Error(),
-			}, "client not found", zap.Error(err), zap.String("service", service), zap.String("ip", t))
+			}, "failed to delete client", zap.Error(err), zap.String("service", service), zap.String("client", t))
 			return
 		}
-		sh.Info("client deleted successfully", zap.String("servcie", service), zap.String("ip", t))
+		sh.Info("client deleted successfully", zap.String("servcie", service), zap.String("client", t))
 		c.JSON(http.StatusOK, httpResponse{
 			Code:    "delete_successfully",
 			Message: "client deleted successfully",
@@ -578,7 +2000,14 @@ func (sh *ServiceHandler) DeleteClients(c *gin.Context) {
 		})
 		return
 	} else { // 전체삭제
-		sh.Client.DeleteAll(service)
+		if err := sh.Client.DeleteAll(c.Request.Context(), service); err != nil {
+			appErr.HandleError(c, sh.Logger, appErr.HttpError{
+				Code:   "internal_server_error",
+				Status: http.StatusInternalServerError,
+				Err:    err.Error(),
+			}, "failed to delete clients", zap.Error(err), zap.String("service", service))
+			return
+		}
 		sh.Info("all clients deleted successfully", zap.String("service", service))
 		c.JSON(http.StatusOK, httpResponse{
 			Code:    "delete_successfully",
@@ -589,7 +2018,29 @@ func (sh *ServiceHandler) DeleteClients(c *gin.Context) {
 	}
 }
 
-func buildDeltaBundle(ctx context.Context, patch *usecase.Patch, patchPath, tarGzPath string) error {
+// ?selector= 파싱, 실패 시 400 응답 후 false
+func (sh *ServiceHandler) labelSelector(c *gin.Context) (clients.Selector, bool) {
+	sel, err := clients.ParseSelector(c.Query("selector"))
+	if err != nil {
+		appErr.HandleError(c, sh.Logger, appErr.HttpError{
+			Code:   "bad_request",
+			Status: http.StatusBadRequest,
+			Err:    err.Error(),
+		}, "invalid label selector", zap.Error(err))
+		return nil, false
+	}
+	return sel, true
+}
+
+func redactClients(records []clients.Record) []clients.Record {
+	redacted := make([]clients.Record, 0, len(records))
+	for _, r := range records {
+		redacted = append(redacted, r.Redacted())
+	}
+	return redacted
+}
+
+func buildDeltaBundle(ctx context.Context, patch *usecase.Patch, patchPath, tarGzPath string, manifest *bundle.Manifest, signer *bundle.Signer) error {
 
 	buf := new(bytes.Buffer)
 	err := utils.EncodeJson(buf, patch)
@@ -606,6 +2057,9 @@ func buildDeltaBundle(ctx context.Context, patch *usecase.Patch, patchPath, tarG
 		ctx,
 		tarGzPath,
 		filepath.Dir(patchPath),
+		manifest,
+		signer,
+		nil,
 	)
 	if err != nil {
 		return fmt.Errorf("%s: %w", appErr.ErrBuildBundle.Error(), err)
@@ -614,7 +2068,7 @@ func buildDeltaBundle(ctx context.Context, patch *usecase.Patch, patchPath, tarG
 	return nil
 }
 
-func buildBundle(ctx context.Context, data *usecase.Data, dataPath, tarGzPath string) error {
+func buildBundle(ctx context.Context, data *usecase.Data, dataPath, tarGzPath string, manifest *bundle.Manifest, signer *bundle.Signer, check func([]bundle.File) error) error {
 	//json형식으로 인코딩
 	buf := new(bytes.Buffer)
 	err := utils.EncodeJson(buf, data)
@@ -622,25 +2076,31 @@ func buildBundle(ctx context.Context, data *usecase.Data, dataPath, tarGzPath st
 		return fmt.Errorf("%s: %w", appErr.ErrEncodeData.Error(), err)
 	}
 
-	//data.json 저장
-	if err := utils.SaveToFileWithLock(ctx, buf, dataPath); err != nil {
-		return fmt.Errorf("%s: %w", appErr.ErrSaveData.Error(), err)
-	}
-
-	//일반-bundle 생성
+	//일반-bundle 생성 (check 실패 시 기존 data.json 유지)
 	err = createBundle(
 		ctx,
 		tarGzPath,
 		filepath.Dir(dataPath),
+		manifest,
+		signer,
+		check,
+		bundle.File{Name: filepath.Base(dataPath), Data: buf.Bytes()},
 	)
 	if err != nil {
 		return fmt.Errorf("%s: %w", appErr.ErrBuildBundle.Error(), err)
 	}
 
+	//data.json 저장
+	if err := utils.SaveToFileWithLock(ctx, buf, dataPath); err != nil {
+		return fmt.Errorf("%s: %w", appErr.ErrSaveData.Error(), err)
+	}
+
 	return nil
 }
 
-func createBundle(ctx context.Context, tarGzPath, sourceDir string) error {
+// check가 nil이 아닌 경우 tmp 파일을 rename하기 전 bundle 파일을 검사하고 실패 시 게시하지 않음
+// extra는 sourceDir의 같은 이름 파일을 대체
+func createBundle(ctx context.Context, tarGzPath, sourceDir string, manifest *bundle.Manifest, signer *bundle.Signer, check func([]bundle.File) error, extra ...bundle.File) error {
 	err := os.MkdirAll(filepath.Dir(tarGzPath), 0755)
 	if err != nil {
 		return err
@@ -656,61 +2116,167 @@ func createBundle(ctx context.Context, tarGzPath, sourceDir string) error {
 	}
 	defer lock.Unlock()
 
-	files, err := os.ReadDir(sourceDir)
+	files, err := readBundleFiles(sourceDir)
 	if err != nil {
-		return fmt.Errorf("failed to read directory: %w", err)
+		return err
+	}
+	for _, e := range extra {
+		replaced := false
+		for i := range files {
+			if files[i].Name == e.Name {
+				files[i], replaced = e, true
+			}
+		}
+		if !replaced {
+			files = append(files, e)
+		}
 	}
 	if len(files) == 0 {
 		return fmt.Errorf("source directory has no file")
 	}
 
+	// .manifest는 소스 디렉토리의 파일이 아닌 생성된 manifest로 대체
+	if manifest == nil {
+		return fmt.Errorf("manifest is nil")
+	}
+	m, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	files = append([]bundle.File{{Name: bundle.ManifestFile, Data: m}}, files...)
+
+	// .manifest를 포함한 모든 파일의 해시를 서명
+	if signer != nil {
+		sig, err := signer.Sign(files)
+		if err != nil {
+			return err
+		}
+		files = append(files, bundle.File{Name: bundle.SignaturesFile, Data: sig})
+	}
+
 	tmpPath := tarGzPath + ".tmp"
 	tarFile, err := os.Create(tmpPath)
 	if err != nil {
 		return fmt.Errorf("failed to create tmp tar.gz: %w", err)
 	}
-	defer tarFile.Close()
 
-	gzipWriter := gzip.NewWriter(tarFile)
-	defer gzipWriter.Close()
-
-	tarWriter := tar.NewWriter(gzipWriter)
-	defer tarWriter.Close()
+	if err := bundle.WriteTarGz(tarFile, files); err != nil {
+		tarFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tarFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
 
-	for _, file := range files {
-		if file.IsDir() || strings.HasSuffix(file.Name(), ".lock") {
-			continue
+	if check != nil {
+		if err := check(files); err != nil {
+			os.Remove(tmpPath)
+			return err
 		}
+	}
+
+	return os.Rename(tmpPath, tarGzPath)
+}
 
-		filePath := filepath.Join(sourceDir, file.Name())
+// sourceDir 하위 디렉토리를 포함한 bundle 파일 (sourceDir 기준 상대경로)
+func readBundleFiles(sourceDir string) ([]bundle.File, error) {
+	var files []bundle.File
 
-		info, err := os.Stat(filePath)
+	err := filepath.WalkDir(sourceDir, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
 
-		header, err := tar.FileInfoHeader(info, file.Name())
+		name, err := filepath.Rel(sourceDir, p)
 		if err != nil {
 			return err
 		}
-		header.Name = file.Name()
+		name = filepath.ToSlash(name)
+		if strings.HasSuffix(name, ".lock") ||
+			strings.HasSuffix(name, ".tmp") ||
+			name == bundle.ManifestFile ||
+			name == bundle.SignaturesFile {
+			return nil
+		}
 
-		if err := tarWriter.WriteHeader(header); err != nil {
+		data, err := os.ReadFile(p)
+		if err != nil {
 			return err
 		}
+		files = append(files, bundle.File{Name: name, Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
 
-		f, err := os.Open(filePath)
+	return files, nil
+}
+
+// delta bundle들의 patch.json을 순서대로 이어붙여 하나의 delta bundle 생성
+func composeDeltaBundle(chain []string, manifest *bundle.Manifest, signer *bundle.Signer) (*bytes.Buffer, error) {
+	composed := &usecase.Patch{}
+
+	for _, path := range chain {
+		f, err := os.Open(path)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("failed to open delta bundle: %w", err)
 		}
-		_, err = io.Copy(tarWriter, f)
+		files, err := bundle.ReadTarGz(f)
 		f.Close()
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+
+		found := false
+		for _, file := range files {
+			if filepath.Base(file.Name) != "patch.json" {
+				continue
+			}
+
+			var patch usecase.Patch
+			if err := json.Unmarshal(file.Data, &patch); err != nil {
+				return nil, fmt.Errorf("%s: failed to decode patch.json: %w", filepath.Base(path), err)
+			}
+			composed.Data = append(composed.Data, patch.Data...)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: patch.json not found", filepath.Base(path))
 		}
 	}
 
-	return os.Rename(tmpPath, tarGzPath)
+	patchBuf := new(bytes.Buffer)
+	if err := utils.EncodeJson(patchBuf, composed); err != nil {
+		return nil, fmt.Errorf("%s: %w", appErr.ErrEncodeData.Error(), err)
+	}
+	m, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	files := []bundle.File{
+		{Name: bundle.ManifestFile, Data: m},
+		{Name: "patch.json", Data: patchBuf.Bytes()},
+	}
+	if signer != nil {
+		sig, err := signer.Sign(files)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, bundle.File{Name: bundle.SignaturesFile, Data: sig})
+	}
+
+	buf := new(bytes.Buffer)
+	if err := bundle.WriteTarGz(buf, files); err != nil {
+		return nil, fmt.Errorf("%s: %w", appErr.ErrBuildBundle.Error(), err)
+	}
+	return buf, nil
 }
 
 func getDataJson(dataPath string) (*usecase.Data, error) {
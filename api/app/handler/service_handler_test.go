@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	contextkey "github.com/jjhwan-h/bundle-server/api/context"
+	"github.com/jjhwan-h/bundle-server/config"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"github.com/jjhwan-h/bundle-server/internal/clients"
+	"github.com/jjhwan-h/bundle-server/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func newTestBundleHandler(t *testing.T) (*gin.Engine, *bundle.Bundle) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	// <opa_data_path>/casb
+	prev := config.Cfg.OpaDataPath
+	config.Cfg.OpaDataPath = t.TempDir()
+	t.Cleanup(func() { config.Cfg.OpaDataPath = prev })
+	dir := filepath.Join(config.Cfg.OpaDataPath, "casb")
+	writeTestBundle(t, dir, bundle.RegularFileName(0, 1), `{}`)
+
+	b, err := bundle.NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, err := b.ETagFromFile(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	sh := &ServiceHandler{
+		Client: &clients.Client{Bundle: map[string]*bundle.Bundle{"casb": b}},
+		Logger: zap.NewNop(),
+	}
+	r := gin.New()
+	r.Use(middleware.ErrorMiddleware())
+	r.GET("/services/:service/bundle", sh.ServeBundle)
+	r.POST("/services/:service/bundle/rollback", sh.RollbackBundle)
+	return r, b
+}
+
+func writeTestBundle(t *testing.T, dir, name, data string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("%v", err)
+	}
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer f.Close()
+	if err := bundle.WriteTarGz(f, []bundle.File{{Name: "data.json", Data: []byte(data)}}); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func TestServeBundleLongPollNotModified(t *testing.T) {
+	prev := config.Cfg.Bundle.LongPollMaxWait
+	config.Cfg.Bundle.LongPollMaxWait = 1
+	t.Cleanup(func() { config.Cfg.Bundle.LongPollMaxWait = prev })
+
+	r, b := newTestBundleHandler(t)
+
+	for _, tc := range []struct {
+		name, url, etag string
+	}{
+		{"regular", "/services/casb/bundle", b.GetEtag()},
+		{"delta", "/services/casb/bundle?type=delta&from=0.1", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			req.Header.Set("Prefer", "modes=snapshot,delta;wait=1")
+			if tc.etag != "" {
+				req.Header.Set("If-None-Match", tc.etag)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotModified {
+				t.Fatalf("expected 304, got %d", w.Code)
+			}
+			// wait가 지난 후에도 OPA가 long polling을 유지하도록
+			if ct := w.Header().Get("Content-Type"); ct != opaBundleMediaType {
+				t.Fatalf("unexpected Content-Type: %q", ct)
+			}
+		})
+	}
+
+	// Prefer: wait가 없으면 일반 응답
+	req := httptest.NewRequest(http.MethodGet, "/services/casb/bundle", nil)
+	req.Header.Set("If-None-Match", b.GetEtag())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Header().Get("Content-Type") == opaBundleMediaType {
+		t.Fatalf("unexpected response without Prefer: %d, %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestServeBundleLongPollEndsOnShutdown(t *testing.T) {
+	prev := config.Cfg.Bundle.LongPollMaxWait
+	config.Cfg.Bundle.LongPollMaxWait = 60
+	t.Cleanup(func() { config.Cfg.Bundle.LongPollMaxWait = prev })
+
+	r, b := newTestBundleHandler(t)
+
+	shutdown, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	req := httptest.NewRequest(http.MethodGet, "/services/casb/bundle", nil)
+	req = req.WithContext(context.WithValue(req.Context(), contextkey.ShutdownKey{}, shutdown))
+	req.Header.Set("Prefer", "wait=60")
+	req.Header.Set("If-None-Match", b.GetEtag())
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	r.ServeHTTP(w, req)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("long polling must end on server shutdown, took %s", elapsed)
+	}
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w.Code)
+	}
+}
+
+func TestServeBundleVersion(t *testing.T) {
+	r, _ := newTestBundleHandler(t)
+
+	for _, tc := range []struct {
+		version string
+		status  int
+	}{
+		{"0.1", http.StatusOK},
+		{"v0.1", http.StatusOK},
+		{"1", http.StatusBadRequest},
+		{"../../etc.1", http.StatusBadRequest},
+		{"9.9", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/services/casb/bundle?version="+tc.version, nil))
+		if w.Code != tc.status {
+			t.Errorf("version=%s: expected %d, got %d", tc.version, tc.status, w.Code)
+		}
+	}
+}
+
+func TestRollbackBundleKeepsDataOnFailure(t *testing.T) {
+	r, b := newTestBundleHandler(t)
+
+	dataPath := filepath.Join(b.DirPath, "regular", "data.json")
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := os.WriteFile(dataPath, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatalf("%v", err)
+	}
+	// index에 기록되지 않은 bundle 파일
+	writeTestBundle(t, b.DirPath, bundle.RegularFileName(0, 9), `{"a":9}`)
+	etag := b.GetEtag()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/casb/bundle/rollback?version=0.9", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	// rollback 실패 시 data.json, Latest, ETag 모두 유지
+	data, err := os.ReadFile(dataPath)
+	if err != nil || string(data) != `{"a":1}` {
+		t.Fatalf("data.json must be reverted, got %s, %v", data, err)
+	}
+	if b.GetEtag() != etag || b.LatestVersion() != "v0.1" {
+		t.Fatalf("latest bundle must not change: %s, %s", b.LatestVersion(), b.GetEtag())
+	}
+}
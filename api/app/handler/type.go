@@ -1,12 +1,109 @@
 package handler
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"github.com/jjhwan-h/bundle-server/internal/clients"
+	"github.com/jjhwan-h/bundle-server/internal/decision"
+	"github.com/jjhwan-h/bundle-server/internal/policy"
+)
+
 type httpResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Status  int    `json:"status"`
 }
 
-type clientGroup []string
-type clientGroupResponse struct {
-	Groups map[string][]string `json:"groups"`
+type clientGroup []clients.Record
+type clientGroupResponse map[string][]clients.Record
+
+// client 등록 요청 (주소 문자열만 보내도 됨)
+type clientRequest struct {
+	ID         string            `json:"id,omitempty"`
+	URL        string            `json:"url"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	HookPath   string            `json:"hook_path,omitempty"`
+	HookMethod string            `json:"hook_method,omitempty"`
+	HealthPath string            `json:"health_path,omitempty"`
+	Secret     string            `json:"secret,omitempty"`
+}
+
+func (r *clientRequest) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*r = clientRequest{URL: url}
+		return nil
+	}
+
+	type request clientRequest
+	return json.Unmarshal(data, (*request)(r))
+}
+
+func (r clientRequest) record() clients.Record {
+	return clients.Record{
+		ID:         r.ID,
+		URL:        r.URL,
+		Labels:     r.Labels,
+		HookPath:   r.HookPath,
+		HookMethod: r.HookMethod,
+		HealthPath: r.HealthPath,
+		Secret:     r.Secret,
+	}
+}
+
+type bundleInfo struct {
+	Version   string    `json:"version"`
+	Revision  int64     `json:"revision,omitempty"`
+	Type      string    `json:"type"`
+	From      string    `json:"from,omitempty"`
+	File      string    `json:"file"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	ETag      string    `json:"etag"`
+	CreatedAt time.Time `json:"created_at"`
+	Trigger   string    `json:"trigger"`
+	Latest    bool      `json:"latest"`
+}
+
+type bundleListResponse struct {
+	Service string       `json:"service"`
+	Latest  string       `json:"latest"`
+	Bundles []bundleInfo `json:"bundles"`
+}
+
+type bundleDetailResponse struct {
+	bundleInfo
+	Manifest *bundle.Manifest  `json:"manifest"`
+	Files    []bundle.FileInfo `json:"files"`
+}
+
+type policyErrorResponse struct {
+	Code        string               `json:"code"`
+	Status      int                  `json:"status"`
+	Err         string               `json:"err"`
+	Diagnostics []policy.Diagnostic  `json:"diagnostics,omitempty"`
+	Failures    []policy.TestFailure `json:"failures,omitempty"`
+}
+
+type policyListResponse struct {
+	Service string   `json:"service"`
+	Modules []string `json:"modules"`
+	Tests   []string `json:"tests"`
+}
+
+type policyHistoryResponse struct {
+	Service   string            `json:"service"`
+	Revisions []policy.Revision `json:"revisions"`
+}
+
+type deliveryListResponse struct {
+	Service    string             `json:"service"`
+	Deliveries []clients.Delivery `json:"deliveries"`
+}
+
+type decisionListResponse struct {
+	Service   string           `json:"service"`
+	Decisions []decision.Event `json:"decisions"`
 }
@@ -1,7 +1,11 @@
 package router
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jjhwan-h/bundle-server/api/app/handler"
@@ -12,8 +16,12 @@ import (
 	"github.com/jjhwan-h/bundle-server/domain/sse/org"
 	"github.com/jjhwan-h/bundle-server/domain/sse/profile"
 	"github.com/jjhwan-h/bundle-server/domain/usecase"
+	"github.com/jjhwan-h/bundle-server/internal/auth"
 	"github.com/jjhwan-h/bundle-server/internal/clients"
+	"github.com/jjhwan-h/bundle-server/internal/decision"
 	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+	"github.com/jjhwan-h/bundle-server/internal/events"
+	"github.com/jjhwan-h/bundle-server/internal/status"
 	"github.com/jjhwan-h/bundle-server/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -29,42 +37,152 @@ func NewServiceRouter(r *gin.Engine, logger *zap.Logger, timeout time.Duration)
 		policy.NewPolicySaasConfigRepo(database.GetDB(config.Cfg.DB.Repository["policy_repo"])),
 	)
 
+	client, err := clients.NewClient(logger, config.Cfg.Clients.Service)
+	if err != nil {
+		return err
+	}
+
 	sh := &handler.ServiceHandler{
 		CasbUsecase: casbUsecase,
-		Client:      clients.NewClient(logger, config.Cfg.Clients.Service),
+		Client:      client,
+		History:     handler.NewPolicyHistory(config.Cfg.Clients.Service),
+		Status:      status.NewStore(),
+		Decisions:   decision.NewStore(filepath.Join(config.Cfg.OpaDataPath, "decisions"), config.Cfg.DecisionLogs.KeepDays),
+		Events:      events.NewBroker(config.Cfg.Events.Buffer),
 		Logger:      logger,
 	}
 
+	authn, err := newAuthenticator()
+	if err != nil {
+		return err
+	}
+	if authn == nil {
+		logger.Warn("auth.token_file, auth.jwks_file and auth.client_certs are not configured, requests are not authenticated")
+	}
+	reader := middleware.Authorize(authn, auth.RoleReader)
+	publisher := middleware.Authorize(authn, auth.RolePublisher)
+	admin := middleware.Authorize(authn, auth.RoleAdmin)
+
+	// retention에 따른 bundle GC
+	if interval := config.Cfg.Bundle.GCInterval; interval > 0 {
+		go sh.Client.RunGC(context.Background(), logger, time.Duration(interval)*time.Minute)
+	}
+
+	// webhook outbox 전송
+	go sh.Client.RunDeliveries(context.Background(), logger)
+
+	// client health check
+	if interval := config.Cfg.Clients.Health.Interval; interval > 0 {
+		go sh.Client.RunHealthCheck(context.Background(), logger, time.Duration(interval)*time.Second)
+	}
+
+	// POST /status (OPA status plugin)
+	r.POST("/status", middleware.TimeOutMiddleware(timeout), middleware.AuthorizeAny(authn, auth.RoleReader), sh.ReceiveStatus)
+
+	// POST /logs (OPA decision log plugin)
+	r.POST("/logs", middleware.TimeOutMiddleware(timeout), middleware.AuthorizeAny(authn, auth.RoleReader), sh.ReceiveDecisionLogs)
+
 	serviceRouter := r.Group("/services", middleware.TimeOutMiddleware(timeout))
 	{
 		// POST /services/:service/data/trigger
-		serviceRouter.POST("/:service/data/trigger", checkAllowedService, sh.BuildDataNBundles)
+		serviceRouter.POST("/:service/data/trigger", checkAllowedService, publisher, sh.BuildDataNBundles)
 
 		// POST /services/:service/policy/trigger
-		serviceRouter.POST("/:service/policy/trigger", checkAllowedService, sh.CreateBundle)
+		serviceRouter.POST("/:service/policy/trigger", checkAllowedService, publisher, sh.CreateBundle)
 
 		// POST /services/:serivce/policy
-		serviceRouter.POST("/:service/policy", checkAllowedService, sh.RegisterPolicy)
+		serviceRouter.POST("/:service/policy", checkAllowedService, publisher, sh.RegisterPolicy)
+
+		// POST /services/:service/policy/restore?rev=x
+		serviceRouter.POST("/:service/policy/restore", checkAllowedService, publisher, sh.RestorePolicy)
+
+		// GET /services/:service/policy/*path
+		// (history, diff?from=x&to=x 포함)
+		serviceRouter.GET("/:service/policy/*path", checkAllowedService, reader, sh.ServePolicy)
+
+		// DELETE /services/:service/policy/*path
+		serviceRouter.DELETE("/:service/policy/*path", checkAllowedService, publisher, sh.DeletePolicy)
 
 		// GET /services/:service/bundle?type=x&version=x.x
-		serviceRouter.GET("/:service/bundle", checkAllowedService, sh.ServeBundle)
+		serviceRouter.GET("/:service/bundle", checkAllowedService, reader, sh.ServeBundle)
+
+		// GET /services/:service/bundles
+		serviceRouter.GET("/:service/bundles", checkAllowedService, reader, sh.ListBundles)
+
+		// GET /services/:service/bundles/:version?type=x&from=x.x
+		serviceRouter.GET("/:service/bundles/:version", checkAllowedService, reader, sh.ServeBundleInfo)
+
+		// POST /services/:service/bundle/rollback?version=x.x
+		serviceRouter.POST("/:service/bundle/rollback", checkAllowedService, publisher, sh.RollbackBundle)
+
+		// GET /services/:service/decisions?from=x&to=x&decision_id=x&path=x&result=x&limit=x
+		serviceRouter.GET("/:service/decisions", checkAllowedService, reader, sh.ServeDecisions)
+
+		// GET /services/:service/rollout
+		serviceRouter.GET("/:service/rollout", checkAllowedService, reader, sh.ServeRollout)
+
+		// GET /services/:service/events (SSE, Last-Event-ID)
+		serviceRouter.GET("/:service/events", checkAllowedService, reader, sh.ServeEvents)
+
+		// GET /services/:service/deliveries?status=x
+		serviceRouter.GET("/:service/deliveries", checkAllowedService, reader, sh.ServeDeliveries)
+
+		// POST /services/:service/deliveries/:id/redeliver
+		serviceRouter.POST("/:service/deliveries/:id/redeliver", checkAllowedService, publisher, sh.Redeliver)
 
 		// POST /services/:service/clients
-		serviceRouter.POST("/:service/clients", checkAllowedService, sh.RegisterClients)
+		serviceRouter.POST("/:service/clients", checkAllowedService, admin, sh.RegisterClients)
 
 		// GET /services/clients
-		serviceRouter.GET("/clients", sh.ServeClients)
+		serviceRouter.GET("/clients", reader, sh.ServeClients)
 
 		// GET /services/:service/clients
-		serviceRouter.GET("/:service/clients", checkAllowedService, sh.ServeServiceClients)
+		serviceRouter.GET("/:service/clients", checkAllowedService, reader, sh.ServeServiceClients)
 
 		// DELETE /services/:service/clients?client=
-		serviceRouter.DELETE("/:service/clients", checkAllowedService, sh.DeleteClients)
+		serviceRouter.DELETE("/:service/clients", checkAllowedService, admin, sh.DeleteClients)
 	}
 
 	return nil
 }
 
+// auth.token_file, auth.jwks_file, auth.client_certs가 모두 비어있으면 nil (인증 비활성화)
+func newAuthenticator() (*auth.Authenticator, error) {
+	cfg := config.Cfg.Auth
+	if cfg.TokenFile == "" && cfg.JWKSFile == "" && len(cfg.ClientCerts) == 0 {
+		return nil, nil
+	}
+
+	authn := &auth.Authenticator{
+		JWT: auth.JWTConfig{
+			Issuer:     cfg.Issuer,
+			Audience:   cfg.Audience,
+			RolesClaim: cfg.RolesClaim,
+		},
+	}
+
+	if len(cfg.ClientCerts) > 0 {
+		authn.ClientCerts = make(map[string][]string, len(cfg.ClientCerts))
+		for _, cert := range cfg.ClientCerts {
+			name := strings.ToLower(cert.Name)
+			authn.ClientCerts[name] = append(authn.ClientCerts[name], cert.Services...)
+		}
+	}
+
+	var err error
+	if cfg.TokenFile != "" {
+		if authn.Tokens, err = auth.NewTokenStore(cfg.TokenFile); err != nil {
+			return nil, fmt.Errorf("failed to load token file: %w", err)
+		}
+	}
+	if cfg.JWKSFile != "" {
+		if authn.Keys, err = auth.NewKeySet(cfg.JWKSFile); err != nil {
+			return nil, fmt.Errorf("failed to load JWKS file: %w", err)
+		}
+	}
+	return authn, nil
+}
+
 func checkAllowedService(c *gin.Context) {
 	service := c.Param("service")
 	for s := range config.Cfg.Clients.Service {
@@ -1,5 +1,10 @@
 package context
 
 const (
-	LogLevel = "log-level"
+	LogLevel       = "log-level"
+	RequestContext = "request-context" // TimeOutMiddleware 적용 전 요청 context (long polling)
+	Identity       = "identity"        // 인증된 사용자 (*auth.Identity)
 )
+
+// 서버 종료(Shutdown) 시 취소되는 context의 key, http.Server.BaseContext에 저장 (SSE, long polling)
+type ShutdownKey struct{}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,8 +11,10 @@ import (
 	"time"
 
 	"github.com/jjhwan-h/bundle-server/api/app/router"
+	contextkey "github.com/jjhwan-h/bundle-server/api/context"
 	"github.com/jjhwan-h/bundle-server/config"
 	"github.com/jjhwan-h/bundle-server/database"
+	"github.com/jjhwan-h/bundle-server/internal/tlsutil"
 
 	"go.uber.org/zap"
 )
@@ -34,6 +37,26 @@ func NewServer(port string, logger *zap.Logger) (*Server, error) {
 		IdleTimeout:       time.Duration(config.Cfg.HTTP.IdleTimeout) * time.Second,
 	}
 
+	// Shutdown은 요청 context를 취소하지 않으므로 SSE, long polling이 종료를 막지 않도록 별도 context로 알림
+	shutdown, cancel := context.WithCancel(context.Background())
+	srv.BaseContext = func(net.Listener) context.Context {
+		return context.WithValue(context.Background(), contextkey.ShutdownKey{}, shutdown)
+	}
+	srv.RegisterOnShutdown(cancel)
+
+	if tlsCfg := config.Cfg.HTTP.TLS; tlsCfg.CertFile != "" {
+		clientAuth, err := tlsutil.ParseClientAuth(tlsCfg.ClientAuth)
+		if err != nil {
+			return nil, fmt.Errorf("[%s]: %w", "TLS_INIT_FAIL", err)
+		}
+		reloader, err := tlsutil.NewReloader(logger, tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCAFile, clientAuth)
+		if err != nil {
+			return nil, fmt.Errorf("[%s]: %w", "TLS_INIT_FAIL", err)
+		}
+		srv.TLSConfig = reloader.TLSConfig()
+		logger.Info("TLS enabled", zap.String("cert", tlsCfg.CertFile), zap.String("client_auth", clientAuth.String()))
+	}
+
 	return &Server{&srv}, nil
 }
 
@@ -45,7 +68,13 @@ func (srv *Server) Start(logger *zap.Logger) {
 
 	srvErr := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ListenAndServeTLS("", "") // 인증서는 TLSConfig에서 로드
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil {
 			srvErr <- err
 		}
 	}()
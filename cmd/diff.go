@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -19,6 +17,7 @@ import (
 	"github.com/jjhwan-h/bundle-server/domain/sse/org"
 	"github.com/jjhwan-h/bundle-server/domain/sse/profile"
 	"github.com/jjhwan-h/bundle-server/domain/usecase"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
 	"github.com/jjhwan-h/bundle-server/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -26,12 +25,18 @@ import (
 var oldBundle string
 var newBundle string
 var output string
+var verifyKey string
+var verifyAlg string
+var verifyKeyID string
+var diffService string
 
 var diffCmd = &cobra.Command{
-	Use:   "diff --old <old_bundle> --new <new_bundle>",
+	Use:   "diff --old <old_bundle> --new <new_bundle> [--service <service>]",
 	Short: "A command that compares two bundles' data.json files and generates a patch.json.",
 	Long: `A command that compares two bundles' data.json files and generates a patch.json.
-	Each bundle must contain exactly one data.json file.`,
+	Each bundle must contain exactly one data.json file.
+	If --verify-key is provided, both bundles must carry a valid .signatures.json.
+	With --service, omitted --verify-* flags default to bundle.service.<service>.signing in config.yaml.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Println("starting..")
 		if oldBundle == "" || newBundle == "" {
@@ -45,11 +50,40 @@ var diffCmd = &cobra.Command{
 			policy.NewPolicySaasConfigRepo(database.GetDB(config.Cfg.DB.Repository["policy_repo"])),
 		)
 
-		oldB, err := ExtractTarGz(oldBundle)
+		if diffService != "" {
+			if err := config.LoadConfig("./config.yaml"); err != nil {
+				log.Fatal("config.yaml is missing or invalid format")
+			}
+			svc, ok := config.Cfg.Bundle.Service[diffService]
+			if !ok {
+				log.Fatalf("unknown service : %s", diffService)
+			}
+			// flag로 지정하지 않은 값만 config의 signing 설정 사용
+			if !cmd.Flags().Changed("verify-key") {
+				verifyKey = svc.Signing.PublicKey
+			}
+			if !cmd.Flags().Changed("verify-alg") && svc.Signing.Algorithm != "" {
+				verifyAlg = svc.Signing.Algorithm
+			}
+			if !cmd.Flags().Changed("verify-key-id") {
+				verifyKeyID = svc.Signing.KeyID
+			}
+		}
+
+		var verifier *bundle.Verifier
+		if verifyKey != "" {
+			v, err := bundle.NewVerifier(verifyAlg, verifyKeyID, verifyKey)
+			if err != nil {
+				log.Fatalf("failed to load verification key : %v", err)
+			}
+			verifier = v
+		}
+
+		oldB, err := ExtractTarGz(oldBundle, verifier)
 		if err != nil {
 			log.Fatalf("failed to unmarshal %s : %v", oldBundle, err)
 		}
-		newB, err := ExtractTarGz(newBundle)
+		newB, err := ExtractTarGz(newBundle, verifier)
 		if err != nil {
 			log.Fatalf("failed to unmarshal %s : %v", newBundle, err)
 		}
@@ -77,37 +111,37 @@ func init() {
 	diffCmd.Flags().StringVar(&oldBundle, "old", "", "Path or name of the old bundle (e.g., regular-v1.0)")
 	diffCmd.Flags().StringVar(&newBundle, "new", "", "Path or name of the new bundle (e.g., regular-v1.1)")
 	diffCmd.Flags().StringVar(&output, "output", "", "Name of the patch.json (e.g., patch.json)")
+	diffCmd.Flags().StringVar(&verifyKey, "verify-key", "", "Path of the PEM public key (or HS256 secret) used to verify bundle signatures")
+	diffCmd.Flags().StringVar(&verifyAlg, "verify-alg", bundle.RS256, "Signing algorithm of the bundles (HS256, RS256, ES256)")
+	diffCmd.Flags().StringVar(&verifyKeyID, "verify-key-id", "", "Expected key id of the bundle signatures")
+	diffCmd.Flags().StringVar(&diffService, "service", "", "Service whose bundle.service.<service>.signing is used for omitted --verify-* flags")
 
 	RootCmd.AddCommand(diffCmd)
 }
 
-func ExtractTarGz(src string) (*usecase.Data, error) {
+// verifier가 nil이 아닌 경우 .signatures.json 검증에 실패한 bundle은 거부
+func ExtractTarGz(src string, verifier *bundle.Verifier) (*usecase.Data, error) {
 	file, err := os.Open(src)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	gzr, err := gzip.NewReader(file)
+	files, err := bundle.ReadTarGz(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		return nil, err
 	}
-	defer gzr.Close()
-
-	tr := tar.NewReader(gzr)
 
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break // 끝
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+	if verifier != nil {
+		if err := verifier.Verify(files); err != nil {
+			return nil, fmt.Errorf("%s: %w", src, err)
 		}
+	}
 
-		if strings.HasSuffix(header.Name, "data.json") {
+	for _, f := range files {
+		if strings.HasSuffix(f.Name, "data.json") {
 			var data usecase.Data
-			dec := json.NewDecoder(tr)
+			dec := json.NewDecoder(bytes.NewReader(f.Data))
 			if err := dec.Decode(&data); err != nil {
 				return nil, fmt.Errorf("json decode error: %w", err)
 			}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/config"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"github.com/jjhwan-h/bundle-server/internal/clients"
+	"github.com/spf13/cobra"
+)
+
+var gcService string
+var gcDryRun bool
+
+var gcCmd = &cobra.Command{
+	Use:   "gc [--service <service>] [--dry-run]",
+	Short: "Remove old bundles according to the retention policy in config.yaml.",
+	Long: `Remove old bundles according to bundle.service.<service>.retention in config.yaml.
+	The latest bundle and pinned versions are always kept.
+	Delta bundles starting from or ending at a removed version are removed as well.
+	With --dry-run, only prints the bundles that would be removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.LoadConfig("./config.yaml"); err != nil {
+			log.Fatal("config.yaml is missing or invalid format")
+		}
+
+		services := []string{gcService}
+		if gcService == "" {
+			services = services[:0]
+			for s := range config.Cfg.Clients.Service {
+				services = append(services, s)
+			}
+		} else if _, ok := config.Cfg.Clients.Service[gcService]; !ok {
+			log.Fatalf("unknown service : %s", gcService)
+		}
+
+		now := time.Now()
+		for _, service := range services {
+			b, err := bundle.NewBundle(fmt.Sprintf("%s/%s", config.Cfg.OpaDataPath, service))
+			if err != nil {
+				log.Fatalf("failed to load bundle index (%s) : %v", service, err)
+			}
+			b.Retention = clients.NewRetention(config.Cfg.Bundle.Service[service].Retention)
+			if !b.Retention.Enabled() {
+				log.Printf("[%s] retention is not configured, skipping", service)
+				continue
+			}
+
+			removed, err := b.GC(context.Background(), now, gcDryRun)
+			for _, e := range removed {
+				if gcDryRun {
+					log.Printf("[%s] would remove %s", service, e.File)
+				} else {
+					log.Printf("[%s] removed %s", service, e.File)
+				}
+			}
+			if err != nil {
+				log.Fatalf("failed to collect bundles (%s) : %v", service, err)
+			}
+			log.Printf("[%s] %d bundle(s), oldest v%d.%d, latest v%d.%d", service,
+				len(b.Entries()), b.Oldest.GetMajor(), b.Oldest.GetMinor(), b.Latest.GetMajor(), b.Latest.GetMinor())
+		}
+	},
+}
+
+func init() {
+	gcCmd.Flags().StringVar(&gcService, "service", "", "Service to collect (default: all services in config.clients.service)")
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Print the bundles that would be removed without removing them")
+
+	RootCmd.AddCommand(gcCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jjhwan-h/bundle-server/config"
+	"github.com/jjhwan-h/bundle-server/internal/auth"
+	"github.com/jjhwan-h/bundle-server/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var tokenServices []string
+var tokenID string
+var tokenRole string
+var tokenWrite bool
+
+var tokenCmd = &cobra.Command{
+	Use:   "token --service <service> [--role <role>] [--id <id>] [--write]",
+	Short: "Generate an API key (bearer token).",
+	Long: `Generate a random API key granting --role on the given services.
+	reader: bundle download and read-only APIs (OPA credentials.bearer)
+	publisher: reader + data/policy trigger, policy upload, rollback
+	admin: publisher + client management
+	The token is printed once; only its sha256 is stored.
+	With --write, the hashed token is appended to auth.token_file in config.yaml.
+	The running server picks up the change without restart, so tokens can be rotated
+	by adding a new token, updating the OPA credentials.bearer config and removing the old entry.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.LoadConfig("./config.yaml"); err != nil {
+			log.Fatal("config.yaml is missing or invalid format")
+		}
+
+		for _, s := range tokenServices {
+			if _, ok := config.Cfg.Clients.Service[s]; !ok && s != "*" {
+				log.Fatalf("unknown service : %s", s)
+			}
+		}
+
+		if _, err := auth.ParseRole(tokenRole); err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		raw, err := auth.GenerateToken()
+		if err != nil {
+			log.Fatalf("failed to generate token : %v", err)
+		}
+		token := auth.Token{ID: tokenID, Services: tokenServices, Role: tokenRole, SHA256: auth.HashToken(raw)}
+		if token.ID == "" {
+			token.ID = token.SHA256[:8]
+		}
+
+		if tokenWrite {
+			path := config.Cfg.Auth.TokenFile
+			if path == "" {
+				log.Fatal("auth.token_file is not configured")
+			}
+
+			f, err := auth.LoadTokenFile(path)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+			f.Tokens = append(f.Tokens, token)
+
+			buf := new(bytes.Buffer)
+			if err := utils.EncodeJson(buf, f); err != nil {
+				log.Fatalf("failed to encode token file : %v", err)
+			}
+			if err := utils.SaveToFileWithLock(context.Background(), buf, path); err != nil {
+				log.Fatalf("failed to save token file : %v", err)
+			}
+			log.Printf("token %q was added to %s", token.ID, path)
+		} else {
+			buf := new(bytes.Buffer)
+			if err := utils.EncodeJson(buf, token); err != nil {
+				log.Fatalf("failed to encode token : %v", err)
+			}
+			fmt.Printf("token file entry:\n%s\n", buf.String())
+		}
+
+		fmt.Printf("bearer token (shown only once):\n%s\n", raw)
+	},
+}
+
+func init() {
+	tokenCmd.Flags().StringSliceVar(&tokenServices, "service", nil, "Services the token is allowed for (\"*\" for all)")
+	tokenCmd.Flags().StringVar(&tokenRole, "role", "reader", "Role granted by the token (reader | publisher | admin)")
+	tokenCmd.Flags().StringVar(&tokenID, "id", "", "Token ID (default: first 8 characters of the token hash)")
+	tokenCmd.Flags().BoolVar(&tokenWrite, "write", false, "Append the hashed token to auth.token_file")
+	tokenCmd.MarkFlagRequired("service")
+
+	RootCmd.AddCommand(tokenCmd)
+}
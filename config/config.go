@@ -14,6 +14,12 @@ type Config struct {
 		ReadHeaderTimeout int `mapstructure:"read_header_timeout"`
 		IdleTimeout       int `mapstructure:"idle_timeout"`
 		ContextTime       int `mapstructure:"context_time"`
+		TLS               struct {
+			CertFile     string `mapstructure:"cert_file"` // 비어있으면 HTTP로 서빙
+			KeyFile      string `mapstructure:"key_file"`
+			ClientCAFile string `mapstructure:"client_ca_file"` // client 인증서 검증용 CA
+			ClientAuth   string `mapstructure:"client_auth"`    // none | request | require | verify_if_given | require_and_verify
+		} `mapstructure:"tls"`
 	} `mapstructure:"http"`
 	DB struct {
 		DataBase        []string          `mapstructure:"database"`
@@ -47,8 +53,80 @@ type Config struct {
 		SSLProxyHeaders      map[string]string `mapstructure:"ssl_proxy_headers"`
 	} `mapstructure:"security"`
 	Clients struct {
-		Service map[string][]string `mapstructure:"service"`
+		Service  map[string][]string  `mapstructure:"service"`
+		Secrets  []ClientSecretConfig `mapstructure:"secrets"` // webhook 서명용 client별 secret
+		Registry RegistryConfig       `mapstructure:"registry"`
+		Health   struct {
+			Interval         int    `mapstructure:"interval"`          // 초, 0이면 비활성화
+			Path             string `mapstructure:"path"`              // 기본값 health
+			Timeout          int    `mapstructure:"timeout"`           // 초, 기본값 3
+			FailureThreshold int    `mapstructure:"failure_threshold"` // 기본값 3. 연속 실패 시 quarantine
+		} `mapstructure:"health"`
+		// webhook 재시도 (0이면 기본값)
+		Delivery struct {
+			InitialBackoff int `mapstructure:"initial_backoff"` // 초, 기본값 1
+			MaxBackoff     int `mapstructure:"max_backoff"`     // 초, 기본값 300
+			MaxAge         int `mapstructure:"max_age"`         // 분, 기본값 1440. 이후 실패하면 재시도 중단
+			Timeout        int `mapstructure:"timeout"`         // 초, 기본값 5. 요청 1건의 timeout
+			Concurrency    int `mapstructure:"concurrency"`     // 기본값 32. 동시 전송 수
+		} `mapstructure:"delivery"`
 	} `mapstructure:"clients"`
+	Bundle struct {
+		GCInterval      int                     `mapstructure:"gc_interval"`        // 분, 0이면 sweeper 비활성화
+		LongPollMaxWait int                     `mapstructure:"long_poll_max_wait"` // 초, Prefer: wait=N 최대값 (0이면 long polling 비활성화)
+		Service         map[string]BundleConfig `mapstructure:"service"`
+	} `mapstructure:"bundle"`
+	Auth struct {
+		TokenFile   string             `mapstructure:"token_file"`   // API key(bearer token) 파일
+		JWKSFile    string             `mapstructure:"jwks_file"`    // JWT 검증용 JWKS 파일
+		Issuer      string             `mapstructure:"issuer"`       // JWT iss (비어있으면 검사하지 않음)
+		Audience    string             `mapstructure:"audience"`     // JWT aud (비어있으면 검사하지 않음)
+		RolesClaim  string             `mapstructure:"roles_claim"`  // JWT role claim (기본값 roles)
+		ClientCerts []ClientCertConfig `mapstructure:"client_certs"` // mTLS client 인증서 => service
+	} `mapstructure:"auth"`
+	DecisionLogs struct {
+		KeepDays int `mapstructure:"keep_days"` // 0이면 삭제하지 않음
+	} `mapstructure:"decision_logs"`
+	Events struct {
+		Heartbeat int `mapstructure:"heartbeat"` // 초. SSE heartbeat 주기 (기본값 15)
+		Buffer    int `mapstructure:"buffer"`    // service별 Last-Event-ID resume용 event 보관 수 (기본값 256)
+	} `mapstructure:"events"`
+}
+
+type BundleConfig struct {
+	Roots     []string        `mapstructure:"roots"`
+	Signing   SigningConfig   `mapstructure:"signing"`
+	Retention RetentionConfig `mapstructure:"retention"`
+}
+
+// viper는 map key의 '.'을 중첩 key로 해석하므로 DNS 이름 등은 list로 설정
+type ClientCertConfig struct {
+	Name     string   `mapstructure:"name"`     // 검증된 client 인증서의 CN 또는 SAN(DNS, URI, email)
+	Services []string `mapstructure:"services"` // reader 권한을 가진 service
+}
+
+type RegistryConfig struct {
+	Type     string `mapstructure:"type"`     // "file"(기본값) | "mysql" | "memory"
+	File     string `mapstructure:"file"`     // file: 기본값 <opa_data_path>/clients.json
+	Database string `mapstructure:"database"` // mysql: db.database 중 하나
+}
+
+type ClientSecretConfig struct {
+	Client     string `mapstructure:"client"`      // clients.service에 등록된 client 주소
+	SecretFile string `mapstructure:"secret_file"` // HMAC-SHA256 shared secret 파일
+}
+
+type SigningConfig struct {
+	Algorithm  string `mapstructure:"algorithm"`   // "HS256" | "RS256" | "ES256"
+	KeyID      string `mapstructure:"key_id"`      // .signatures.json keyid
+	PrivateKey string `mapstructure:"private_key"` // 서명용 PEM 파일 (HS256인 경우 secret 파일)
+	PublicKey  string `mapstructure:"public_key"`  // 검증용 PEM 파일 (HS256인 경우 secret 파일), diff --service의 --verify-key 기본값
+}
+
+type RetentionConfig struct {
+	KeepLast int      `mapstructure:"keep_last"` // 최근 N개 regular bundle 보존
+	KeepDays int      `mapstructure:"keep_days"` // D일 이내 생성된 bundle 보존
+	Pinned   []string `mapstructure:"pinned"`    // 항상 보존할 버전 (e.g. "v1.3")
 }
 
 var Cfg Config
@@ -195,49 +195,87 @@ func convertCode(num int) (string, error) {
 }
 
 // GET 업데이트된 CASB 정책 데이터
+// 생성된 patch는 순서대로 적용되며, 각 op의 index는 이전 op가 적용된 배열 기준으로 계산됨
 func getCasbPatch(oldData *Data, data *Data) (changes []PatchData) {
 	// default_effect
 	if oldData.DefaultEffect != data.DefaultEffect {
 		changes = append(changes, PatchData{
-			Op:    "replace",
+			Op:    opReplace,
 			Path:  "/default_effect",
 			Value: data.DefaultEffect,
 		})
 	}
+
 	// policies
-	for _, newPolicy := range data.Policies {
-		isExist := false
-		for idx, oldPolicy := range oldData.Policies {
-			if newPolicy.PolicyID == oldPolicy.PolicyID {
-				isExist = true
-				changes = append(changes, compareCasbPolicies(oldPolicy, newPolicy, idx)...)
-				break
-			}
-		}
-		if !isExist {
+	if oldData.Policies == nil {
+		// 기존 policies가 null인 경우 index 기반 op를 적용할 수 없으므로 배열 전체를 upsert
+		if data.Policies != nil {
 			changes = append(changes, PatchData{
-				Op:    "upsert",
+				Op:    opUpsert,
 				Path:  "/policies",
-				Value: newPolicy,
+				Value: data.Policies,
 			})
 		}
+		return
+	}
+	if data.Policies == nil {
+		changes = append(changes, PatchData{
+			Op:    opReplace,
+			Path:  "/policies",
+			Value: data.Policies,
+		})
+		return
+	}
+
+	newIDs := make(map[uint]struct{}, len(data.Policies))
+	for _, newPolicy := range data.Policies {
+		newIDs[newPolicy.PolicyID] = struct{}{}
 	}
 
+	// 1. 삭제된 정책 제거: 뒤에서부터 제거하여 앞쪽 index가 밀리지 않도록 함
+	current := make([]Policy, 0, len(oldData.Policies)) // patch 적용 중의 배열 상태
+	var removed []int
 	for idx, oldPolicy := range oldData.Policies {
-		isExist := false
-		for _, newPolicy := range data.Policies {
-			if oldPolicy.PolicyID == newPolicy.PolicyID {
-				isExist = true
-				break
-			}
+		if _, ok := newIDs[oldPolicy.PolicyID]; ok {
+			current = append(current, oldPolicy)
+		} else {
+			removed = append(removed, idx)
 		}
-		if !isExist {
-			changes = append(changes, PatchData{Op: "remove",
+	}
+	for i := len(removed) - 1; i >= 0; i-- {
+		changes = append(changes, PatchData{
+			Op:   opRemove,
+			Path: fmt.Sprintf("/policies/%d", removed[i]),
+		})
+	}
+
+	// 2. 위치별 비교: 같은 정책이면 변경된 필드만, 순서가 바뀐 경우 해당 위치를 교체, 신규 정책은 append
+	for idx, newPolicy := range data.Policies {
+		switch {
+		case idx < len(current) && current[idx].PolicyID == newPolicy.PolicyID:
+			changes = append(changes, compareCasbPolicies(current[idx], newPolicy, idx)...)
+		case idx < len(current):
+			changes = append(changes, PatchData{
+				Op:    opReplace,
 				Path:  fmt.Sprintf("/policies/%d", idx),
-				Value: nil,
+				Value: newPolicy,
+			})
+		default:
+			changes = append(changes, PatchData{
+				Op:    opUpsert,
+				Path:  "/policies/-",
+				Value: newPolicy,
 			})
 		}
 	}
+
+	// 3. 중복 id 등으로 남은 뒤쪽 정책 제거
+	for idx := len(current) - 1; idx >= len(data.Policies); idx-- {
+		changes = append(changes, PatchData{
+			Op:   opRemove,
+			Path: fmt.Sprintf("/policies/%d", idx),
+		})
+	}
 	return
 }
 
@@ -245,31 +283,39 @@ func compareCasbPolicies(oldPolicy, newPolicy Policy, idx int) (changes []PatchD
 	prefix := fmt.Sprintf("/policies/%d", idx)
 
 	if newPolicy.PolicyID != oldPolicy.PolicyID {
-		changes = append(changes, PatchData{"replace", prefix + "/id", newPolicy.PolicyID})
+		changes = append(changes, PatchData{opReplace, prefix + "/id", newPolicy.PolicyID})
 	}
 	if newPolicy.Priority != oldPolicy.Priority {
-		changes = append(changes, PatchData{"replace", prefix + "/priority", newPolicy.Priority})
+		changes = append(changes, PatchData{opReplace, prefix + "/priority", newPolicy.Priority})
 	}
 	if newPolicy.PolicyName != oldPolicy.PolicyName {
-		changes = append(changes, PatchData{"replace", prefix + "/name", newPolicy.PolicyName})
+		changes = append(changes, PatchData{opReplace, prefix + "/name", newPolicy.PolicyName})
 	}
 	if newPolicy.Effect != oldPolicy.Effect {
-		changes = append(changes, PatchData{"replace", prefix + "/effect", newPolicy.Effect})
+		changes = append(changes, PatchData{opReplace, prefix + "/effect", newPolicy.Effect})
 	}
-	if !slices.Equal(newPolicy.Subject.Users, oldPolicy.Subject.Users) {
-		changes = append(changes, PatchData{"replace", prefix + "/subject/users", newPolicy.Subject.Users})
+	if !equalStrings(newPolicy.Subject.Users, oldPolicy.Subject.Users) {
+		changes = append(changes, PatchData{opReplace, prefix + "/subject/users", newPolicy.Subject.Users})
 	}
-	if !slices.Equal(newPolicy.Subject.Groups, oldPolicy.Subject.Groups) {
-		changes = append(changes, PatchData{"replace", prefix + "/subject/groups", newPolicy.Subject.Groups})
+	if !equalStrings(newPolicy.Subject.Groups, oldPolicy.Subject.Groups) {
+		changes = append(changes, PatchData{opReplace, prefix + "/subject/groups", newPolicy.Subject.Groups})
 	}
 
 	if !equalService(newPolicy.Services, oldPolicy.Services) {
-		changes = append(changes, PatchData{"replace", prefix + "/services", newPolicy.Services})
+		changes = append(changes, PatchData{opReplace, prefix + "/services", newPolicy.Services})
 	}
 
 	return
 }
 
+// null과 빈 배열은 data.json에서 서로 다른 값이므로 구분
+func equalStrings(a, b []string) bool {
+	if (a == nil) != (b == nil) {
+		return false
+	}
+	return slices.Equal(a, b)
+}
+
 // 내부 객체 값은 동일하지만 객체 순서가 바뀐 경우에도 다른 것으로 처리됨.
 func equalService(a, b []category.CategoryService) bool {
 	return reflect.DeepEqual(a, b)
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jjhwan-h/bundle-server/internal/utils"
+)
+
+// OPA delta bundle patch operations
+// https://www.openpolicyagent.org/docs/latest/management-bundles/#delta-bundles
+const (
+	opUpsert  = "upsert"
+	opRemove  = "remove"
+	opReplace = "replace"
+)
+
+// data에 patch를 순서대로 적용한 새로운 Data를 반환 (data는 변경하지 않음)
+// OPA와 동일하게 upsert는 없는 경로를 생성하고 배열 index에는 삽입, "-"는 append로 처리
+func ApplyPatch(data *Data, patch *Patch) (*Data, error) {
+	if data == nil || patch == nil {
+		return nil, fmt.Errorf("data and patch must not be nil")
+	}
+
+	doc, err := utils.StructToMap(data)
+	if err != nil {
+		return nil, handleErr("convert data to map", err)
+	}
+
+	var root any = doc
+	for i, p := range patch.Data {
+		segments, err := parsePointer(p.Path)
+		if err != nil {
+			return nil, fmt.Errorf("patch[%d]: %w", i, err)
+		}
+
+		value, err := toGeneric(p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("patch[%d]: %w", i, err)
+		}
+
+		root, err = applyOp(root, segments, p.Op, value)
+		if err != nil {
+			return nil, fmt.Errorf("patch[%d] %s %s: %w", i, p.Op, p.Path, err)
+		}
+	}
+
+	b, err := json.Marshal(root)
+	if err != nil {
+		return nil, handleErr("encode patched data", err)
+	}
+
+	var patched Data
+	if err := json.Unmarshal(b, &patched); err != nil {
+		return nil, handleErr("decode patched data", err)
+	}
+	return &patched, nil
+}
+
+func applyOp(node any, segments []string, op string, value any) (any, error) {
+	key := segments[0]
+	last := len(segments) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, exists := n[key]
+		if last {
+			switch op {
+			case opUpsert:
+				n[key] = value
+			case opReplace:
+				if !exists {
+					return nil, fmt.Errorf("path not found")
+				}
+				n[key] = value
+			case opRemove:
+				if !exists {
+					return nil, fmt.Errorf("path not found")
+				}
+				delete(n, key)
+			default:
+				return nil, fmt.Errorf("unsupported op")
+			}
+			return n, nil
+		}
+
+		if !exists {
+			if op != opUpsert {
+				return nil, fmt.Errorf("path not found")
+			}
+			child = map[string]any{}
+		}
+		updated, err := applyOp(child, segments[1:], op, value)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = updated
+		return n, nil
+
+	case []any:
+		if last && key == "-" {
+			if op != opUpsert {
+				return nil, fmt.Errorf("'-' is only allowed with upsert")
+			}
+			return append(n, value), nil
+		}
+
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 {
+			return nil, fmt.Errorf("invalid array index %q", key)
+		}
+
+		if last {
+			switch op {
+			case opUpsert:
+				if idx > len(n) {
+					return nil, fmt.Errorf("array index %d out of range", idx)
+				}
+				return slices.Insert(n, idx, value), nil
+			case opReplace:
+				if idx >= len(n) {
+					return nil, fmt.Errorf("array index %d out of range", idx)
+				}
+				n[idx] = value
+				return n, nil
+			case opRemove:
+				if idx >= len(n) {
+					return nil, fmt.Errorf("array index %d out of range", idx)
+				}
+				return slices.Delete(n, idx, idx+1), nil
+			default:
+				return nil, fmt.Errorf("unsupported op")
+			}
+		}
+
+		if idx >= len(n) {
+			return nil, fmt.Errorf("array index %d out of range", idx)
+		}
+		updated, err := applyOp(n[idx], segments[1:], op, value)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = updated
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("cannot traverse %T", node)
+	}
+}
+
+// JSON pointer(RFC 6901) => path segments
+func parsePointer(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") || path == "/" {
+		return nil, fmt.Errorf("invalid patch path %q", path)
+	}
+
+	segments := strings.Split(path[1:], "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+func toGeneric(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
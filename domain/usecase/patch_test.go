@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/jjhwan-h/bundle-server/domain/casb/policy"
+	"github.com/jjhwan-h/bundle-server/domain/integration/category"
+)
+
+// quick.Check용 Data 생성기
+// 작은 id 범위를 사용해 추가/삭제/순서변경/필드변경이 섞이도록 함
+type randomData struct {
+	*Data
+}
+
+func (randomData) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(randomData{genData(r)})
+}
+
+func genData(r *rand.Rand) *Data {
+	effects := []string{"allow", "deny"}
+
+	data := &Data{
+		DefaultEffect: effects[r.Intn(2)],
+		Policies:      []Policy{},
+	}
+
+	ids := r.Perm(8)[:r.Intn(8)]
+	for _, id := range ids {
+		data.Policies = append(data.Policies, Policy{
+			Priority:   int16(r.Intn(3)),
+			PolicyID:   uint(id),
+			PolicyName: []string{"a", "b"}[r.Intn(2)],
+			Subject: Subject{
+				Users:  genStrings(r, "u"),
+				Groups: genStrings(r, "g"),
+			},
+			Services: genServices(r),
+			Effect:   effects[r.Intn(2)],
+		})
+	}
+	return data
+}
+
+func genStrings(r *rand.Rand, prefix string) []string {
+	if r.Intn(10) == 0 {
+		return nil
+	}
+	out := []string{}
+	for i := 0; i < r.Intn(3); i++ {
+		out = append(out, prefix+string(rune('0'+r.Intn(3))))
+	}
+	return out
+}
+
+func genServices(r *rand.Rand) []category.CategoryService {
+	out := []category.CategoryService{}
+	for i := 0; i < r.Intn(3); i++ {
+		out = append(out, category.CategoryService{
+			CID:    uint16(r.Intn(3)),
+			Action: policy.Action(r.Intn(2)),
+		})
+	}
+	return out
+}
+
+func TestApplyPatchRoundTrip(t *testing.T) {
+	property := func(oldData, newData randomData) bool {
+		patched, err := ApplyPatch(oldData.Data, &Patch{Data: getCasbPatch(oldData.Data, newData.Data)})
+		if err != nil {
+			t.Logf("apply failed: %v", err)
+			return false
+		}
+		return jsonEqual(t, patched, newData.Data)
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyPatchRemovesCorrectPolicies(t *testing.T) {
+	oldData := &Data{DefaultEffect: "deny", Policies: []Policy{
+		{PolicyID: 1, Subject: Subject{Users: []string{}, Groups: []string{}}},
+		{PolicyID: 2, Subject: Subject{Users: []string{}, Groups: []string{}}},
+		{PolicyID: 3, Subject: Subject{Users: []string{}, Groups: []string{}}},
+		{PolicyID: 4, Subject: Subject{Users: []string{}, Groups: []string{}}},
+	}}
+	newData := &Data{DefaultEffect: "deny", Policies: []Policy{
+		oldData.Policies[1],
+		oldData.Policies[3],
+		{PolicyID: 5, Subject: Subject{Users: []string{"u1"}, Groups: []string{}}},
+	}}
+
+	patch, err := (&casbUsecase{}).BuildPatchJson(oldData, newData)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	patched, err := ApplyPatch(oldData, patch)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !jsonEqual(t, patched, newData) {
+		b, _ := json.MarshalIndent(patch, "", "  ")
+		t.Fatalf("patched data does not match new data\npatch: %s", b)
+	}
+}
+
+func TestApplyPatchDoesNotModifyInput(t *testing.T) {
+	oldData := &Data{DefaultEffect: "deny", Policies: []Policy{}}
+	patch := &Patch{Data: []PatchData{
+		{Op: opReplace, Path: "/default_effect", Value: "allow"},
+	}}
+
+	if _, err := ApplyPatch(oldData, patch); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if oldData.DefaultEffect != "deny" {
+		t.Fatalf("input data was modified")
+	}
+}
+
+func TestApplyPatchInvalidOps(t *testing.T) {
+	data := &Data{DefaultEffect: "deny", Policies: []Policy{}}
+
+	cases := []PatchData{
+		{Op: opRemove, Path: "/policies/0"},
+		{Op: opReplace, Path: "/unknown"},
+		{Op: opRemove, Path: "/policies/-"},
+		{Op: "move", Path: "/default_effect"},
+		{Op: opUpsert, Path: "policies"},
+	}
+	for _, c := range cases {
+		if _, err := ApplyPatch(data, &Patch{Data: []PatchData{c}}); err == nil {
+			t.Errorf("expected error for %s %s", c.Op, c.Path)
+		}
+	}
+}
+
+func jsonEqual(t *testing.T, a, b *Data) bool {
+	t.Helper()
+
+	ja, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(ja) != string(jb) {
+		t.Logf("got:  %s\nwant: %s", ja, jb)
+		return false
+	}
+	return true
+}
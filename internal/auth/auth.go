@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// API key(token 파일), JWT(JWKS 파일), client 인증서(mTLS)로 요청자 인증
+// 설정되지 않은 방식은 사용하지 않음
+type Authenticator struct {
+	Tokens      *TokenStore
+	Keys        *KeySet
+	JWT         JWTConfig
+	ClientCerts map[string][]string // 인증서 CN/SAN(소문자) => reader 권한을 가진 service
+}
+
+// Authorization 헤더 => Identity
+// 실패 시 ErrUnauthorized
+func (a *Authenticator) Authenticate(header string) (*Identity, error) {
+	raw, ok := BearerToken(header)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	if a.Tokens != nil {
+		if t, ok := a.Tokens.Lookup(raw); ok {
+			return t.Identity(), nil
+		}
+	}
+
+	if a.Keys != nil && strings.Count(raw, ".") == 2 {
+		id, err := a.Keys.Verify(raw, a.JWT, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		}
+		return id, nil
+	}
+
+	return nil, ErrUnauthorized
+}
+
+// 검증된 client 인증서 => Identity (CN, SAN 중 ClientCerts에 등록된 이름)
+func (a *Authenticator) AuthenticateCert(state *tls.ConnectionState) (*Identity, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(a.ClientCerts) == 0 {
+		return nil, false
+	}
+	leaf := state.VerifiedChains[0][0]
+
+	names := append([]string{leaf.Subject.CommonName}, leaf.DNSNames...)
+	names = append(names, leaf.EmailAddresses...)
+	for _, u := range leaf.URIs {
+		names = append(names, u.String())
+	}
+
+	id := &Identity{Method: "mtls", Grants: map[string]Role{}}
+	for _, name := range names {
+		// DNS 이름은 대소문자를 구분하지 않음
+		services, ok := a.ClientCerts[strings.ToLower(name)]
+		if !ok || name == "" {
+			continue
+		}
+		if id.Subject == "" {
+			id.Subject = name
+		}
+		for _, service := range services {
+			id.Grants[service] = RoleReader
+		}
+	}
+	if id.Subject == "" {
+		return nil, false
+	}
+	return id, true
+}
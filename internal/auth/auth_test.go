@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+)
+
+func TestAuthenticateCert(t *testing.T) {
+	authn := &Authenticator{ClientCerts: map[string][]string{
+		"opa-casb.internal":             {"casb"},
+		"spiffe://example.org/opa/ztna": {"ztna"},
+	}}
+
+	spiffe, _ := url.Parse("spiffe://example.org/opa/ztna")
+	leaf := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "OPA-CASB.internal"},
+		DNSNames: []string{"opa.local"},
+		URIs:     []*url.URL{spiffe},
+	}
+
+	id, ok := authn.AuthenticateCert(&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}})
+	if !ok {
+		t.Fatalf("expected certificate identity")
+	}
+	if id.Method != "mtls" || id.Subject != "OPA-CASB.internal" {
+		t.Fatalf("unexpected identity: %+v", id)
+	}
+	if !id.Can("casb", RoleReader) || !id.Can("ztna", RoleReader) || id.Can("casb", RolePublisher) {
+		t.Fatalf("unexpected grants: %v", id.Grants)
+	}
+
+	// 검증되지 않은 인증서
+	if _, ok := authn.AuthenticateCert(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}); ok {
+		t.Fatalf("unverified certificate must not authenticate")
+	}
+
+	// 등록되지 않은 이름
+	other := &x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}}
+	if _, ok := authn.AuthenticateCert(&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{other}}}); ok {
+		t.Fatalf("unknown certificate must not authenticate")
+	}
+}
@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 로컬 JWKS 파일 (https://datatracker.ietf.org/doc/html/rfc7517)
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"` // RSA | EC
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+
+	N string `json:"n,omitempty"` // RSA
+	E string `json:"e,omitempty"`
+
+	Crv string `json:"crv,omitempty"` // EC
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWT 검증 조건
+type JWTConfig struct {
+	Issuer     string // 비어있으면 검사하지 않음
+	Audience   string // 비어있으면 검사하지 않음
+	RolesClaim string // e.g. roles => ["casb:publisher", "admin"]
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type publicKey struct {
+	kid string
+	key crypto.PublicKey // *rsa.PublicKey | *ecdsa.PublicKey
+}
+
+// JWKS 파일 기반 JWT 검증 (파일 수정 시 재시작 없이 반영)
+type KeySet struct {
+	file watchedFile
+	keys []publicKey
+
+	mu sync.Mutex
+}
+
+func NewKeySet(path string) (*KeySet, error) {
+	ks := &KeySet{file: watchedFile{path: path}}
+	if err := ks.reload(time.Now()); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// 서명, 만료, issuer, audience를 검증하고 role claim으로 Identity 생성
+func (ks *KeySet) Verify(token string, cfg JWTConfig, now time.Time) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeJSONSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	if err := ks.verifySignature(header, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeJSONSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+	return identityFromClaims(claims, cfg, now)
+}
+
+func (ks *KeySet) verifySignature(header jwtHeader, input, sig []byte) error {
+	ks.mu.Lock()
+	_ = ks.reload(time.Now()) // 읽기 실패 시 기존 key 유지
+	keys := ks.keys
+	ks.mu.Unlock()
+
+	for _, k := range keys {
+		if header.Kid != "" && k.kid != header.Kid {
+			continue
+		}
+		if err := verifyJWS(header.Alg, k.key, input, sig); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid signature (alg %s, kid %q)", header.Alg, header.Kid)
+}
+
+// caller가 ks.mu를 잡고 있거나 생성 중이어야 함
+func (ks *KeySet) reload(now time.Time) error {
+	changed, err := ks.file.changed(now)
+	if err != nil || !changed {
+		return err
+	}
+
+	b, err := os.ReadFile(ks.file.path)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var set JWKS
+	if err := json.Unmarshal(b, &set); err != nil {
+		return fmt.Errorf("failed to decode JWKS file: %w", err)
+	}
+
+	keys := make([]publicKey, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			return fmt.Errorf("JWKS key %q: %w", jwk.Kid, err)
+		}
+		keys = append(keys, publicKey{kid: jwk.Kid, key: key})
+	}
+	ks.keys = keys
+	ks.file.loaded()
+	return nil
+}
+
+func (k *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid e")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+}
+
+func verifyJWS(alg string, key crypto.PublicKey, input, sig []byte) error {
+	var (
+		h          hash.Hash
+		cryptoHash crypto.Hash
+	)
+	switch alg {
+	case "RS256", "ES256":
+		h, cryptoHash = sha256.New(), crypto.SHA256
+	case "RS384", "ES384":
+		h, cryptoHash = sha512.New384(), crypto.SHA384
+	case "RS512", "ES512":
+		h, cryptoHash = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+	h.Write(input)
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match RSA key", alg)
+		}
+		return rsa.VerifyPKCS1v15(key, cryptoHash, digest, sig)
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match EC key", alg)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key type: %T", key)
+}
+
+func identityFromClaims(claims map[string]any, cfg JWTConfig, now time.Time) (*Identity, error) {
+	if exp, ok := claims["exp"].(float64); !ok || now.After(time.Unix(int64(exp), 0)) {
+		return nil, fmt.Errorf("token is expired or has no exp claim")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("token is not valid yet")
+	}
+	if cfg.Issuer != "" && claims["iss"] != cfg.Issuer {
+		return nil, fmt.Errorf("unexpected issuer: %v", claims["iss"])
+	}
+	if cfg.Audience != "" && !hasAudience(claims["aud"], cfg.Audience) {
+		return nil, fmt.Errorf("unexpected audience: %v", claims["aud"])
+	}
+
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, fmt.Errorf("token has no sub claim")
+	}
+
+	id := &Identity{Subject: sub, Method: "jwt", Grants: map[string]Role{}}
+	rolesClaim := cfg.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = "roles"
+	}
+	roles, _ := claims[rolesClaim].([]any)
+	for _, r := range roles {
+		s, ok := r.(string)
+		if !ok {
+			continue
+		}
+		service, role, err := parseGrant(s)
+		if err != nil {
+			continue // 알 수 없는 role은 무시
+		}
+		if role > id.Grants[service] {
+			id.Grants[service] = role
+		}
+	}
+	return id, nil
+}
+
+func hasAudience(aud any, want string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == want
+	case []any:
+		for _, a := range v {
+			if a == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeJSONSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("empty value")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
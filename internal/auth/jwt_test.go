@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeJWKS(t *testing.T, path string, key *ecdsa.PublicKey, kid string) {
+	t.Helper()
+
+	b, _ := json.Marshal(JWKS{Keys: []JWK{{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}}})
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, &key.PublicKey, "k1")
+
+	ks, err := NewKeySet(path)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	a := &Authenticator{Keys: ks, JWT: JWTConfig{Issuer: "idp", Audience: "bundle-server"}}
+
+	now := time.Now()
+	claims := map[string]any{
+		"sub":   "alice",
+		"iss":   "idp",
+		"aud":   []string{"bundle-server"},
+		"exp":   now.Add(time.Hour).Unix(),
+		"roles": []string{"casb:publisher", "reader", "unknown:role"},
+	}
+
+	id, err := a.Authenticate("Bearer " + signES256(t, key, "k1", claims))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if id.Subject != "alice" || !id.Can("casb", RolePublisher) || id.Can("casb", RoleAdmin) ||
+		!id.Can("ztna", RoleReader) || id.Can("ztna", RolePublisher) {
+		t.Fatalf("unexpected identity: %+v", id)
+	}
+
+	for name, mutate := range map[string]func(map[string]any){
+		"expired":     func(c map[string]any) { c["exp"] = now.Add(-time.Minute).Unix() },
+		"issuer":      func(c map[string]any) { c["iss"] = "other" },
+		"audience":    func(c map[string]any) { c["aud"] = "other" },
+		"missing sub": func(c map[string]any) { delete(c, "sub") },
+		"not yet":     func(c map[string]any) { c["nbf"] = now.Add(time.Hour).Unix() },
+	} {
+		c := map[string]any{}
+		for k, v := range claims {
+			c[k] = v
+		}
+		mutate(c)
+		if _, err := a.Authenticate("Bearer " + signES256(t, key, "k1", c)); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("%s: expected ErrUnauthorized, got %v", name, err)
+		}
+	}
+
+	// 다른 key로 서명한 token
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := a.Authenticate("Bearer " + signES256(t, other, "k1", claims)); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for foreign key, got %v", err)
+	}
+}
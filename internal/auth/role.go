@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 상위 role은 하위 role의 권한을 모두 포함
+type Role int
+
+const (
+	RoleNone      Role = iota
+	RoleReader         // bundle 다운로드, 조회
+	RolePublisher      // trigger, 정책 업로드/삭제/복원, rollback
+	RoleAdmin          // client 관리
+)
+
+// 모든 service에 대한 권한
+const AllServices = "*"
+
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(s) {
+	case "", "reader":
+		return RoleReader, nil
+	case "publisher":
+		return RolePublisher, nil
+	case "admin":
+		return RoleAdmin, nil
+	}
+	return RoleNone, fmt.Errorf("unknown role: %q", s)
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleReader:
+		return "reader"
+	case RolePublisher:
+		return "publisher"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// 인증된 사용자 (API key 또는 JWT)
+type Identity struct {
+	Subject string          `json:"subject"`
+	Method  string          `json:"method"` // token | jwt
+	Grants  map[string]Role `json:"grants"` // service("*" 포함) => role
+}
+
+// service에 role 이상의 권한이 있는지 여부
+// service가 비어있는 경우 "*" 권한만 인정
+func (id *Identity) Can(service string, role Role) bool {
+	granted := id.Grants[AllServices]
+	if service != "" && id.Grants[service] > granted {
+		granted = id.Grants[service]
+	}
+	return granted >= role
+}
+
+// 어느 service든 role 이상의 권한이 있는지 여부 (OPA status, decision log 수신 등)
+func (id *Identity) CanAny(role Role) bool {
+	for _, granted := range id.Grants {
+		if granted >= role {
+			return true
+		}
+	}
+	return false
+}
+
+// "casb:publisher" => casb, publisher / "admin" => *, admin
+func parseGrant(s string) (string, Role, error) {
+	service, role, ok := strings.Cut(s, ":")
+	if !ok {
+		service, role = AllServices, s
+	}
+	r, err := ParseRole(role)
+	if err != nil {
+		return "", RoleNone, err
+	}
+	return service, r, nil
+}
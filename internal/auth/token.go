@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnauthorized = errors.New("missing or invalid bearer token")
+	ErrForbidden    = errors.New("permission denied")
+)
+
+// 변경 여부 확인 간격 (파일 수정 시 재시작 없이 반영)
+const reloadInterval = time.Second
+
+// 수정 시간이 바뀌면 다시 읽는 설정 파일 (token, JWKS)
+type watchedFile struct {
+	path    string
+	modTime time.Time // 마지막으로 읽은 파일의 수정 시간
+	pending time.Time // changed에서 확인한 수정 시간
+	checked time.Time
+	ok      bool // 한 번 이상 읽었는지 여부
+}
+
+// 마지막으로 읽은 이후 파일이 바뀌었는지 여부 (reloadInterval마다 확인)
+func (w *watchedFile) changed(now time.Time) (bool, error) {
+	if w.ok && now.Sub(w.checked) < reloadInterval {
+		return false, nil
+	}
+	w.checked = now
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", w.path, err)
+	}
+	w.pending = info.ModTime()
+	return !w.ok || !w.pending.Equal(w.modTime), nil
+}
+
+// changed 이후 파일을 정상적으로 읽은 경우 호출
+func (w *watchedFile) loaded() {
+	w.modTime = w.pending
+	w.ok = true
+}
+
+// 파일이 없는 상태를 읽은 것으로 처리 (생성되면 changed에서 감지)
+func (w *watchedFile) missing() {
+	w.modTime = time.Time{}
+	w.ok = true
+}
+
+// token 파일 (평문 token은 저장하지 않음)
+//
+//	{"tokens": [{"id": "opa-casb-1", "services": ["casb"], "role": "reader", "sha256": "<hex>"}]}
+type TokenFile struct {
+	Tokens []Token `json:"tokens"`
+}
+
+type Token struct {
+	ID       string   `json:"id"`
+	Services []string `json:"services"`       // "*"는 모든 service
+	Role     string   `json:"role,omitempty"` // reader(기본값) | publisher | admin
+	SHA256   string   `json:"sha256"`         // token의 sha256 (hex)
+}
+
+func (t *Token) Identity() *Identity {
+	role, _ := ParseRole(t.Role) // LoadTokenFile에서 검증
+	id := &Identity{Subject: t.ID, Method: "token", Grants: map[string]Role{}}
+	for _, service := range t.Services {
+		id.Grants[service] = role
+	}
+	return id
+}
+
+// token 파일 기반 bearer token 검증
+// 파일의 수정 시간이 바뀌면 다음 요청에서 다시 읽음 (token rotation)
+// 파일이 없으면 token 없이 시작하고, `token --write`로 생성되면 반영
+type TokenStore struct {
+	file   watchedFile
+	tokens map[string]*Token // sha256 => token
+
+	mu sync.Mutex
+}
+
+func NewTokenStore(path string) (*TokenStore, error) {
+	s := &TokenStore{file: watchedFile{path: path}}
+	if err := s.reload(time.Now()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// 등록된 token인 경우 반환
+func (s *TokenStore) Lookup(raw string) (*Token, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 읽기 실패 시 기존 token 유지
+	_ = s.reload(time.Now())
+
+	t, ok := s.tokens[HashToken(raw)]
+	return t, ok
+}
+
+// caller가 s.mu를 잡고 있거나 생성 중이어야 함
+func (s *TokenStore) reload(now time.Time) error {
+	changed, err := s.file.changed(now)
+	if errors.Is(err, os.ErrNotExist) {
+		// 파일이 삭제된 경우 기존 token도 모두 폐기
+		s.tokens = nil
+		s.file.missing()
+		return nil
+	}
+	if err != nil || !changed {
+		return err
+	}
+
+	f, err := LoadTokenFile(s.file.path)
+	if err != nil {
+		return err
+	}
+
+	tokens := make(map[string]*Token, len(f.Tokens))
+	for i, t := range f.Tokens {
+		tokens[strings.ToLower(t.SHA256)] = &f.Tokens[i]
+	}
+	s.tokens = tokens
+	s.file.loaded()
+	return nil
+}
+
+func LoadTokenFile(path string) (*TokenFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &TokenFile{}, nil
+		}
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var f TokenFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode token file: %w", err)
+	}
+	for _, t := range f.Tokens {
+		if len(t.SHA256) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid sha256 for token %q", t.ID)
+		}
+		if _, err := ParseRole(t.Role); err != nil {
+			return nil, fmt.Errorf("token %q: %w", t.ID, err)
+		}
+	}
+	return &f, nil
+}
+
+// "Bearer <token>" => token
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// 32 byte 난수 token
+func GenerateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTokenFile(t *testing.T, path string, tokens ...Token) {
+	t.Helper()
+
+	b, err := json.Marshal(TokenFile{Tokens: tokens})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func TestTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokenFile(t, path,
+		Token{ID: "casb", Services: []string{"casb"}, SHA256: HashToken("casb-secret")},
+		Token{ID: "ops", Services: []string{"*"}, Role: "admin", SHA256: HashToken("ops-secret")},
+	)
+
+	s, err := NewTokenStore(path)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	tok, ok := s.Lookup("casb-secret")
+	if !ok {
+		t.Fatalf("token was not found")
+	}
+	if id := tok.Identity(); !id.Can("casb", RoleReader) || id.Can("casb", RolePublisher) || id.Can("ztna", RoleReader) {
+		t.Fatalf("unexpected grants: %v", id.Grants)
+	}
+
+	tok, ok = s.Lookup("ops-secret")
+	if !ok || !tok.Identity().Can("ztna", RoleAdmin) {
+		t.Fatalf("unexpected token: %+v", tok)
+	}
+
+	if _, ok := s.Lookup("unknown"); ok {
+		t.Fatalf("unknown token was accepted")
+	}
+
+	// rotation: 파일 변경 후 reloadInterval이 지나면 반영
+	writeTokenFile(t, path, Token{ID: "casb-2", Services: []string{"casb"}, SHA256: HashToken("rotated")})
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("%v", err)
+	}
+	s.file.checked = time.Time{}
+
+	if _, ok := s.Lookup("rotated"); !ok {
+		t.Fatalf("rotated token was not accepted")
+	}
+	if _, ok := s.Lookup("casb-secret"); ok {
+		t.Fatalf("revoked token was accepted")
+	}
+}
+
+func TestTokenStoreMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+
+	s, err := NewTokenStore(path)
+	if err != nil {
+		t.Fatalf("missing token file must not be an error: %v", err)
+	}
+	if _, ok := s.Lookup("casb-secret"); ok {
+		t.Fatalf("token was accepted without token file")
+	}
+
+	// `token --write`로 파일이 생성되면 재시작 없이 반영
+	writeTokenFile(t, path, Token{ID: "casb", Services: []string{"casb"}, SHA256: HashToken("casb-secret")})
+	s.file.checked = time.Time{}
+	if _, ok := s.Lookup("casb-secret"); !ok {
+		t.Fatalf("token was not accepted after token file was created")
+	}
+
+	// 파일이 삭제되면 모든 token 폐기
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("%v", err)
+	}
+	s.file.checked = time.Time{}
+	if _, ok := s.Lookup("casb-secret"); ok {
+		t.Fatalf("token was accepted after token file was removed")
+	}
+}
+
+func TestLoadTokenFileRejectsUnknownRole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokenFile(t, path, Token{ID: "x", Services: []string{"casb"}, Role: "root", SHA256: HashToken("x")})
+
+	if _, err := LoadTokenFile(path); err == nil {
+		t.Fatalf("expected error for unknown role")
+	}
+}
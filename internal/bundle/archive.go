@@ -0,0 +1,125 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// bundle(tar.gz) 내부 파일
+type File struct {
+	Name string
+	Data []byte
+}
+
+// bundle 내부 파일 정보 (내용 제외)
+type FileInfo struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func WriteTarGz(w io.Writer, files []File) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	now := time.Now()
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    f.Name,
+			Mode:    0644,
+			Size:    int64(len(f.Data)),
+			ModTime: now,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write tar header(%s): %w", f.Name, err)
+		}
+		if _, err := tarWriter.Write(f.Data); err != nil {
+			return fmt.Errorf("failed to write tar entry(%s): %w", f.Name, err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func ReadTarGz(r io.Reader) ([]File, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzr.Close()
+
+	var files []File
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+		}
+
+		if !header.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry(%s): %w", header.Name, err)
+		}
+		files = append(files, File{
+			Name: strings.TrimPrefix(path.Clean("/"+header.Name), "/"), // "./data.json" => "data.json"
+			Data: data,
+		})
+	}
+
+	return files, nil
+}
+
+// bundle 파일의 .manifest와 파일 목록 (.manifest가 없는 경우 nil)
+func Inspect(bundlePath string) (*Manifest, []FileInfo, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	files, err := ReadTarGz(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		manifest *Manifest
+		infos    = make([]FileInfo, 0, len(files))
+	)
+	for _, file := range files {
+		sum := sha256.Sum256(file.Data)
+		infos = append(infos, FileInfo{
+			Name:   file.Name,
+			Size:   int64(len(file.Data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+
+		if file.Name == ManifestFile {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(file.Data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode %s: %w", ManifestFile, err)
+			}
+		}
+	}
+
+	return manifest, infos, nil
+}
@@ -1,6 +1,7 @@
 package bundle
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -18,49 +19,66 @@ type Bundle struct {
 	DirPath string
 	Oldest  *Version
 	Latest  *Version
+	Roots   []string // .manifest roots
+	Signer  *Signer  // nil인 경우 서명하지 않음
 
-	etag string // 가장 최신 번들 해시값
+	Retention Retention // GC 보존 정책
 
-	mu sync.RWMutex
+	etag    string        // 가장 최신 번들 해시값
+	index   *Index        // 영구 저장되는 버전 index (index.json)
+	changed chan struct{} // etag가 바뀌면 close 후 새로 생성 (long polling 대기자 알림)
+
+	mu      sync.RWMutex
+	publish sync.Mutex // NextVersion부터 Record까지 (같은 버전의 bundle 파일을 동시에 생성하지 않도록)
+}
+
+// bundle 게시(버전 발급, 파일 생성, index 기록)를 직렬화, 반환된 함수로 해제
+func (b *Bundle) LockPublish() func() {
+	b.publish.Lock()
+	return b.publish.Unlock
 }
 
+// Major는 기존 regular-vX.Y 파일명과의 호환을 위해 유지
+// 새 버전은 Minor만 증가 (자리올림 없음)
 type Version struct {
 	Major int
-	Minor int8 // 0~9
+	Minor int
 
 	mu sync.Mutex
 }
 
 type ReadOnlyVersion interface {
 	GetMajor() int
-	GetMinor() int8
+	GetMinor() int
 }
 
 var versionPattern = regexp.MustCompile(`^regular-v(\d+)\.(\d+).tar.gz$`)
 
-func NewBundle(dirPath string) *Bundle {
-	versions, err := extractVersionsFromDir(dirPath)
-	if err != nil || len(versions) == 0 {
-		return &Bundle{
-			Latest: &Version{
-				Major: 0,
-				Minor: 0,
-			},
-			Oldest: &Version{
-				Major: 0,
-				Minor: 0,
-			},
-			DirPath: dirPath,
-		}
+// index.json에서 버전 정보를 읽고, index.json이 없는 경우 디렉토리의 기존 bundle 파일로 index를 생성
+func NewBundle(dirPath string) (*Bundle, error) {
+	b := &Bundle{
+		Latest:  &Version{},
+		Oldest:  &Version{},
+		DirPath: dirPath,
 	}
 
-	return &Bundle{
-		Latest:  latestVersion(versions).(*Version),
-		Oldest:  oldestVersion(versions).(*Version),
-		DirPath: dirPath,
+	idx, err := loadIndex(dirPath)
+	if err != nil {
+		return nil, err
 	}
+	if idx == nil {
+		idx, err = migrateIndex(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate bundle index: %w", err)
+		}
+	}
+
+	b.index = idx
+	b.syncVersions()
+	return b, nil
 }
 
+// 기존 regular-vX.Y.tar.gz 파일 탐색 (index.json 마이그레이션 용도)
 func extractVersionsFromDir(dirPath string) ([]ReadOnlyVersion, error) {
 	var versions []ReadOnlyVersion
 
@@ -69,6 +87,10 @@ func extractVersionsFromDir(dirPath string) ([]ReadOnlyVersion, error) {
 			return err
 		}
 
+		if d.IsDir() && path != dirPath {
+			return filepath.SkipDir // 하위 디렉토리(regular/, delta/ 등) 제외
+		}
+
 		if !d.Type().IsRegular() {
 			return nil // skip non-files
 		}
@@ -78,7 +100,7 @@ func extractVersionsFromDir(dirPath string) ([]ReadOnlyVersion, error) {
 		if len(matches) == 3 {
 			major, _ := strconv.Atoi(matches[1])
 			minor, _ := strconv.Atoi(matches[2])
-			versions = append(versions, &Version{Major: major, Minor: int8(minor)})
+			versions = append(versions, &Version{Major: major, Minor: minor})
 		}
 
 		return nil
@@ -118,21 +140,27 @@ func oldestVersion(versions []ReadOnlyVersion) ReadOnlyVersion {
 }
 
 func (b *Bundle) ETagFromFile() (string, error) {
-	p := fmt.Sprintf("%s/regular-v%d.%d.tar.gz", b.DirPath, b.Latest.Major, b.Latest.Minor)
+	major, minor := b.Latest.GetMajor(), b.Latest.GetMinor()
+
+	p := filepath.Join(b.DirPath, RegularFileName(major, minor))
 	f, err := os.Open(p)
 	if err != nil {
-		return "", fmt.Errorf("failed to open bundle: regular-v%d.%d.tar.gz : %w", b.Latest.Major, b.Latest.Minor, err)
+		return "", fmt.Errorf("failed to open bundle: %s : %w", RegularFileName(major, minor), err)
 	}
 	defer f.Close()
 
 	hasher := sha256.New()
 	if _, err := io.Copy(hasher, f); err != nil {
-		return "", fmt.Errorf("failed to hash bundle: regular-v%d.%d.tar.gz : %w", b.Latest.Major, b.Latest.Minor, err)
+		return "", fmt.Errorf("failed to hash bundle: %s : %w", RegularFileName(major, minor), err)
 	}
 
-	sum := hasher.Sum(nil)
-	b.etag = `"` + hex.EncodeToString(sum) + `"`
-	return `"` + hex.EncodeToString(sum) + `"`, nil // ETag는 따옴표 포함
+	etag := `"` + hex.EncodeToString(hasher.Sum(nil)) + `"` // ETag는 따옴표 포함
+
+	b.mu.Lock()
+	b.setEtag(etag)
+	b.mu.Unlock()
+
+	return etag, nil
 }
 
 func (b *Bundle) GetEtag() string {
@@ -142,26 +170,48 @@ func (b *Bundle) GetEtag() string {
 	return b.etag
 }
 
-func (v *Version) IncrementVersion() {
-	v.mu.Lock()
-	defer v.mu.Unlock()
+// etag가 바뀐 경우 WaitForChange 대기자를 깨움 (caller가 b.mu를 잡고 있어야 함)
+// trigger, rollback 핸들러는 Record, Rollback을 통해 새 bundle을 알림
+func (b *Bundle) setEtag(etag string) {
+	if b.etag == etag {
+		return
+	}
+	b.etag = etag
 
-	if v.Minor < 9 {
-		v.Minor++
-	} else {
-		v.Major++
-		v.Minor = 0
+	if b.changed != nil {
+		close(b.changed)
+		b.changed = nil
 	}
 }
 
-func (v *Version) NextVersion() (int, int8) {
+// etag가 현재 값과 다르거나 바뀔 때까지 대기
+// ctx가 먼저 끝나면 false
+func (b *Bundle) WaitForChange(ctx context.Context, etag string) bool {
+	b.mu.Lock()
+	if b.etag != etag {
+		b.mu.Unlock()
+		return true
+	}
+	if b.changed == nil {
+		b.changed = make(chan struct{})
+	}
+	changed := b.changed
+	b.mu.Unlock()
+
+	select {
+	case <-changed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (v *Version) Set(major, minor int) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	if v.Minor < 9 {
-		return v.Major, v.Minor + 1
-	}
-	return v.Major + 1, 0
+	v.Major = major
+	v.Minor = minor
 }
 
 func (v *Version) GetMajor() int {
@@ -170,7 +220,7 @@ func (v *Version) GetMajor() int {
 	return v.Major
 }
 
-func (v *Version) GetMinor() int8 {
+func (v *Version) GetMinor() int {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	return v.Minor
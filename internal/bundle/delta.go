@@ -0,0 +1,174 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	deltaPattern = regexp.MustCompile(`^delta-v(\d+)\.(\d+)-v(\d+)\.(\d+)\.tar\.gz$`)
+
+	ErrBrokenChain     = errors.New("delta chain is broken")
+	ErrVersionNotFound = errors.New("bundle version not found")
+	ErrRolledBack      = errors.New("latest bundle was rolled back")
+	ErrVersionExists   = errors.New("bundle version already recorded")
+)
+
+type versionKey struct {
+	major int
+	minor int
+}
+
+type deltaEdge struct {
+	to   versionKey
+	path string
+}
+
+func RegularFileName(major, minor int) string {
+	return fmt.Sprintf("regular-%s.tar.gz", Revision(major, minor))
+}
+
+// from 버전에서 to 버전으로의 단일 step delta bundle
+func DeltaFileName(fromMajor, fromMinor, toMajor, toMinor int) string {
+	return fmt.Sprintf("delta-%s-%s.tar.gz", Revision(fromMajor, fromMinor), Revision(toMajor, toMinor))
+}
+
+// "X.Y" 또는 "vX.Y" => major, minor
+func ParseVersion(s string) (int, int, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid version format: %q", s)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major < 0 {
+		return 0, 0, fmt.Errorf("invalid major version: %q", s)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return 0, 0, fmt.Errorf("invalid minor version: %q", s)
+	}
+
+	return major, minor, nil
+}
+
+// from 버전에서 Latest까지 순서대로 적용해야 하는 delta bundle 경로 목록
+// 가장 적은 수의 delta로 도달하는 경로를 반환하며, 도달할 수 없는 경우 ErrBrokenChain
+func (b *Bundle) DeltaChain(fromMajor, fromMinor int) ([]string, error) {
+	edges, err := b.deltaEdges()
+	if err != nil {
+		return nil, err
+	}
+
+	from := versionKey{fromMajor, fromMinor}
+	latest := versionKey{b.Latest.GetMajor(), b.Latest.GetMinor()}
+	if from == latest {
+		return nil, nil
+	}
+
+	// BFS: prev[v]는 v에 도달한 직전 버전과 delta 경로
+	type step struct {
+		from versionKey
+		path string
+	}
+	prev := map[versionKey]step{}
+	visited := map[versionKey]bool{from: true}
+	queue := []versionKey{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, e := range edges[cur] {
+			if visited[e.to] {
+				continue
+			}
+			visited[e.to] = true
+			prev[e.to] = step{from: cur, path: e.path}
+			queue = append(queue, e.to)
+		}
+	}
+
+	if !visited[latest] {
+		return nil, fmt.Errorf("%w: v%d.%d -> v%d.%d", ErrBrokenChain, fromMajor, fromMinor, latest.major, latest.minor)
+	}
+
+	var chain []string
+	for v := latest; v != from; v = prev[v].from {
+		chain = append([]string{prev[v].path}, chain...)
+	}
+	return chain, nil
+}
+
+// Latest로 끝나는 단일 step delta bundle 중 가장 최근 버전에서 시작하는 것
+// rollback으로 Latest보다 새로운 버전이 기록되어 있으면 client가 이미 더 새로운 버전일 수 있으므로 ErrRolledBack
+func (b *Bundle) LatestDelta() (string, error) {
+	latest := versionKey{b.Latest.GetMajor(), b.Latest.GetMinor()}
+	for _, e := range b.Entries() {
+		major, minor, err := ParseVersion(e.Version)
+		if err == nil && lessVersion(latest, versionKey{major, minor}) {
+			return "", fmt.Errorf("%w: %s is newer than v%d.%d", ErrRolledBack, e.Version, latest.major, latest.minor)
+		}
+	}
+
+	edges, err := b.deltaEdges()
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		found *versionKey
+		path  string
+	)
+	for from, es := range edges {
+		for _, e := range es {
+			if e.to != latest {
+				continue
+			}
+			if found == nil || lessVersion(*found, from) {
+				f := from
+				found = &f
+				path = e.path
+			}
+		}
+	}
+
+	if found == nil {
+		return "", fmt.Errorf("%w: no delta bundle for v%d.%d", ErrBrokenChain, latest.major, latest.minor)
+	}
+	return path, nil
+}
+
+// index.json에 기록된 delta bundle => 버전 그래프
+func (b *Bundle) deltaEdges() (map[versionKey][]deltaEdge, error) {
+	edges := map[versionKey][]deltaEdge{}
+	for _, e := range b.Entries() {
+		if e.Type != TypeDelta {
+			continue
+		}
+
+		fromMajor, fromMinor, err := ParseVersion(e.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delta entry %s: %w", e.File, err)
+		}
+		toMajor, toMinor, err := ParseVersion(e.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delta entry %s: %w", e.File, err)
+		}
+
+		from := versionKey{fromMajor, fromMinor}
+		edges[from] = append(edges[from], deltaEdge{
+			to:   versionKey{toMajor, toMinor},
+			path: filepath.Join(b.DirPath, e.File),
+		})
+	}
+
+	return edges, nil
+}
+
+func lessVersion(a, b versionKey) bool {
+	return a.major < b.major || (a.major == b.major && a.minor < b.minor)
+}
@@ -0,0 +1,109 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeltaChain(t *testing.T) {
+	dir := t.TempDir()
+	idx := &Index{Latest: "v1.5"}
+	for _, step := range [][4]int{
+		{1, 1, 1, 2},
+		{1, 2, 1, 3},
+		{1, 3, 1, 4},
+		{1, 4, 1, 5},
+		{1, 3, 1, 5}, // rollback 등으로 생긴 지름길
+	} {
+		idx.Entries = append(idx.Entries, IndexEntry{
+			Version: Revision(step[2], step[3]),
+			Type:    TypeDelta,
+			From:    Revision(step[0], step[1]),
+			File:    DeltaFileName(step[0], step[1], step[2], step[3]),
+		})
+	}
+
+	b := &Bundle{DirPath: dir, Latest: &Version{Major: 1, Minor: 5}, index: idx}
+
+	chain, err := b.DeltaChain(1, 2)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	want := []string{DeltaFileName(1, 2, 1, 3), DeltaFileName(1, 3, 1, 5)}
+	if len(chain) != len(want) {
+		t.Fatalf("unexpected chain: %v", chain)
+	}
+	for i := range want {
+		if filepath.Base(chain[i]) != want[i] {
+			t.Fatalf("unexpected chain: %v", chain)
+		}
+	}
+
+	chain, err = b.DeltaChain(1, 5)
+	if err != nil || len(chain) != 0 {
+		t.Fatalf("expected empty chain for latest version, got %v, %v", chain, err)
+	}
+
+	if _, err := b.DeltaChain(1, 0); !errors.Is(err, ErrBrokenChain) {
+		t.Fatalf("expected broken chain, got %v", err)
+	}
+
+	latest, err := b.LatestDelta()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if filepath.Base(latest) != DeltaFileName(1, 4, 1, 5) {
+		t.Fatalf("unexpected latest delta: %s", latest)
+	}
+}
+
+func TestLatestDeltaAfterRollback(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, RegularFileName(0, 1), `{"a":1}`)
+	writeBundle(t, dir, RegularFileName(0, 2), `{"a":2}`)
+	writeBundle(t, dir, RegularFileName(0, 3), `{"a":3}`)
+	writeBundle(t, dir, DeltaFileName(0, 1, 0, 2), `{}`)
+	writeBundle(t, dir, DeltaFileName(0, 2, 0, 3), `{}`)
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	latest, err := b.LatestDelta()
+	if err != nil || filepath.Base(latest) != DeltaFileName(0, 2, 0, 3) {
+		t.Fatalf("unexpected latest delta: %s, %v", latest, err)
+	}
+
+	if _, err := b.Rollback(context.Background(), 0, 2); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// v0.3을 받은 client에 v0.1 => v0.2 delta를 주면 잘못된 data에 적용됨
+	if latest, err := b.LatestDelta(); !errors.Is(err, ErrRolledBack) {
+		t.Fatalf("expected ErrRolledBack after rollback, got %s, %v", latest, err)
+	}
+
+	// from이 지정된 경우 이전 버전에서의 chain은 그대로 사용
+	chain, err := b.DeltaChain(0, 1)
+	if err != nil || len(chain) != 1 || filepath.Base(chain[0]) != DeltaFileName(0, 1, 0, 2) {
+		t.Fatalf("unexpected chain: %v, %v", chain, err)
+	}
+	if _, err := b.DeltaChain(0, 3); !errors.Is(err, ErrBrokenChain) {
+		t.Fatalf("expected broken chain from rolled back version, got %v", err)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	major, minor, err := ParseVersion("v1.3")
+	if err != nil || major != 1 || minor != 3 {
+		t.Fatalf("unexpected result: %d, %d, %v", major, minor, err)
+	}
+
+	for _, s := range []string{"", "1", "1.a", "1.2.3", "-1.0"} {
+		if _, _, err := ParseVersion(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
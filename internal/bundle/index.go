@@ -0,0 +1,371 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/internal/utils"
+)
+
+const (
+	IndexFile = "index.json"
+
+	TriggerData      = "data"      // POST /services/:service/data/trigger
+	TriggerPolicy    = "policy"    // POST /services/:service/policy/trigger
+	TriggerMigration = "migration" // index.json 도입 이전에 생성된 bundle
+)
+
+// service별 bundle 버전 index (<opa_data_path>/<service>/index.json)
+type Index struct {
+	Latest  string       `json:"latest"` // 현재 배포중인 regular bundle 버전 (e.g. v1.3)
+	Entries []IndexEntry `json:"entries"`
+}
+
+type IndexEntry struct {
+	Revision   int64     `json:"revision,omitempty"` // regular bundle마다 1씩 증가하는 번호
+	Version    string    `json:"version"`            // e.g. v1.3
+	Type       string    `json:"type"`               // regular | delta
+	From       string    `json:"from,omitempty"`     // delta bundle의 시작 버전
+	File       string    `json:"file"`
+	CreatedAt  time.Time `json:"created_at"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Trigger    string    `json:"trigger"`
+	DataSHA256 string    `json:"data_sha256,omitempty"` // bundle 내 data.json 해시
+}
+
+func loadIndex(dirPath string) (*Index, error) {
+	b, err := os.ReadFile(filepath.Join(dirPath, IndexFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", IndexFile, err)
+	}
+
+	var idx Index
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", IndexFile, err)
+	}
+	return &idx, nil
+}
+
+// 기존 regular-vX.Y.tar.gz, delta-vX.Y-vX.Y.tar.gz 파일로 index.json 생성
+func migrateIndex(dirPath string) (*Index, error) {
+	idx := &Index{}
+
+	if _, err := os.Stat(dirPath); errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+
+	versions, err := extractVersionsFromDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return oldestVersion([]ReadOnlyVersion{versions[i], versions[j]}) == versions[i]
+	})
+
+	for i, v := range versions {
+		e, err := migrateEntry(dirPath, RegularFileName(v.GetMajor(), v.GetMinor()))
+		if err != nil {
+			return nil, err
+		}
+		e.Revision = int64(i + 1)
+		e.Version = Revision(v.GetMajor(), v.GetMinor())
+		e.Type = TypeRegular
+		idx.Entries = append(idx.Entries, *e)
+	}
+	if latest := latestVersion(versions); latest != nil {
+		idx.Latest = Revision(latest.GetMajor(), latest.GetMinor())
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		m := deltaPattern.FindStringSubmatch(entry.Name())
+		if !entry.Type().IsRegular() || len(m) != 5 {
+			continue
+		}
+
+		e, err := migrateEntry(dirPath, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		e.Version = "v" + m[3] + "." + m[4]
+		e.From = "v" + m[1] + "." + m[2]
+		e.Type = TypeDelta
+		idx.Entries = append(idx.Entries, *e)
+	}
+
+	if len(idx.Entries) == 0 {
+		return idx, nil
+	}
+
+	if err := writeIndex(context.Background(), dirPath, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func migrateEntry(dirPath, file string) (*IndexEntry, error) {
+	path := filepath.Join(dirPath, file)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, size, dataSum, err := describeFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IndexEntry{
+		File:       file,
+		CreatedAt:  info.ModTime(),
+		SHA256:     sum,
+		Size:       size,
+		Trigger:    TriggerMigration,
+		DataSHA256: dataSum,
+	}, nil
+}
+
+// bundle 파일의 sha256, 크기, data.json 해시
+func describeFile(path string) (string, int64, string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("failed to read bundle: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+
+	files, err := ReadTarGz(bytes.NewReader(raw))
+	if err != nil {
+		return "", 0, "", fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	var dataSum string
+	for _, f := range files {
+		if filepath.Base(f.Name) != "data.json" {
+			continue
+		}
+		dataSum, err = HashFile(f.Name, f.Data)
+		if err != nil {
+			return "", 0, "", err
+		}
+		break
+	}
+
+	return hex.EncodeToString(sum[:]), int64(len(raw)), dataSum, nil
+}
+
+func writeIndex(ctx context.Context, dirPath string, idx *Index) error {
+	buf := new(bytes.Buffer)
+	if err := utils.EncodeJson(buf, idx); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", IndexFile, err)
+	}
+	if err := utils.SaveToFileWithLock(ctx, buf, filepath.Join(dirPath, IndexFile)); err != nil {
+		return fmt.Errorf("failed to save %s: %w", IndexFile, err)
+	}
+	return nil
+}
+
+// 새로 생성된 bundle 파일을 index에 기록
+// regular bundle인 경우 revision을 발급하고 Latest, ETag를 해당 버전으로 갱신
+// 이미 기록된 파일이면 ErrVersionExists (동시 게시로 같은 버전을 덮어쓴 경우)
+func (b *Bundle) Record(ctx context.Context, e IndexEntry) (*IndexEntry, error) {
+	sum, size, dataSum, err := describeFile(filepath.Join(b.DirPath, e.File))
+	if err != nil {
+		return nil, err
+	}
+	e.SHA256 = sum
+	e.Size = size
+	e.DataSHA256 = dataSum
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.reloadIndex(); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range b.index.Entries {
+		if entry.File == e.File {
+			return nil, fmt.Errorf("%w: %s", ErrVersionExists, e.File)
+		}
+	}
+
+	idx := &Index{Latest: b.index.Latest, Entries: append([]IndexEntry{}, b.index.Entries...)}
+
+	if e.Type == TypeRegular {
+		e.Revision = b.index.maxRevision() + 1
+		idx.Latest = e.Version
+	}
+	idx.Entries = append(idx.Entries, e)
+
+	if err := writeIndex(ctx, b.DirPath, idx); err != nil {
+		return nil, err
+	}
+	b.index = idx
+
+	if e.Type == TypeRegular {
+		b.setEtag(`"` + e.SHA256 + `"`)
+	}
+	b.syncVersions()
+
+	return &e, nil
+}
+
+// 이미 생성된 regular bundle을 Latest로 재지정
+// 이후 생성되는 bundle은 index의 최대 버전 다음 버전을 사용하며 rollback된 버전을 기준으로 delta를 생성
+func (b *Bundle) Rollback(ctx context.Context, major, minor int) (*IndexEntry, error) {
+	version := Revision(major, minor)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.reloadIndex(); err != nil {
+		return nil, err
+	}
+
+	entry := b.index.regular(version)
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %s", ErrVersionNotFound, version)
+	}
+	// index와 ETag를 함께 갱신 (index만 바뀐 채로 이전 ETag를 제공하지 않도록)
+	sum, _, _, err := describeFile(filepath.Join(b.DirPath, entry.File))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrVersionNotFound, version, err)
+	}
+
+	idx := &Index{Latest: version, Entries: b.index.Entries}
+	if err := writeIndex(ctx, b.DirPath, idx); err != nil {
+		return nil, err
+	}
+	b.index = idx
+	b.syncVersions()
+	b.setEtag(`"` + sum + `"`)
+
+	e := *entry
+	return &e, nil
+}
+
+// version에 해당하는 bundle entry
+// delta bundle인 경우 from이 비어있으면 가장 최근에 생성된 것
+func (b *Bundle) Entry(version, bundleType, from string) (*IndexEntry, error) {
+	var found *IndexEntry
+	for _, e := range b.Entries() {
+		if e.Version != version || e.Type != bundleType || (from != "" && e.From != from) {
+			continue
+		}
+		e := e
+		found = &e
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrVersionNotFound, bundleType, version)
+	}
+	return found, nil
+}
+
+// 현재 배포중인 regular bundle 버전
+func (b *Bundle) LatestVersion() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.index.Latest
+}
+
+// index에 기록된 모든 bundle (생성 순)
+func (b *Bundle) Entries() []IndexEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	entries := make([]IndexEntry, len(b.index.Entries))
+	copy(entries, b.index.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries
+}
+
+// 다음 regular bundle 버전
+// rollback으로 Latest가 낮아진 경우에도 이미 발급된 버전을 재사용하지 않도록 index의 최대 버전 기준
+// 발급한 버전을 예약하지 않으므로 caller는 Record까지 LockPublish를 유지
+func (b *Bundle) NextVersion() (int, int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	major, minor := b.Latest.GetMajor(), b.Latest.GetMinor()
+	for _, e := range b.index.Entries {
+		if e.Type != TypeRegular {
+			continue
+		}
+		ma, mi, err := ParseVersion(e.Version)
+		if err != nil {
+			continue
+		}
+		if lessVersion(versionKey{major, minor}, versionKey{ma, mi}) {
+			major, minor = ma, mi
+		}
+	}
+	return major, minor + 1
+}
+
+// index => Latest, Oldest
+func (b *Bundle) syncVersions() {
+	var versions []ReadOnlyVersion
+	for _, e := range b.index.Entries {
+		if e.Type != TypeRegular {
+			continue
+		}
+		major, minor, err := ParseVersion(e.Version)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, &Version{Major: major, Minor: minor})
+	}
+
+	if latest := latestVersion(versions); latest != nil {
+		b.Latest.Set(latest.GetMajor(), latest.GetMinor())
+	}
+	if major, minor, err := ParseVersion(b.index.Latest); err == nil {
+		b.Latest.Set(major, minor)
+	}
+	if oldest := oldestVersion(versions); oldest != nil {
+		b.Oldest.Set(oldest.GetMajor(), oldest.GetMinor())
+	}
+}
+
+func (idx *Index) regular(version string) *IndexEntry {
+	for i, e := range idx.Entries {
+		if e.Type == TypeRegular && e.Version == version {
+			return &idx.Entries[i]
+		}
+	}
+	return nil
+}
+
+func (idx *Index) maxRevision() int64 {
+	var max int64
+	for _, e := range idx.Entries {
+		if e.Revision > max {
+			max = e.Revision
+		}
+	}
+	return max
+}
@@ -0,0 +1,254 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeBundle(t *testing.T, dir, name, data string) {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer f.Close()
+
+	if err := WriteTarGz(f, []File{{Name: "data.json", Data: []byte(data)}}); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func TestNewBundleMigratesIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, RegularFileName(0, 9), `{"a":1}`)
+	writeBundle(t, dir, RegularFileName(1, 0), `{"a":2}`)
+	writeBundle(t, dir, DeltaFileName(0, 9, 1, 0), `{}`)
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if b.Latest.GetMajor() != 1 || b.Latest.GetMinor() != 0 {
+		t.Fatalf("unexpected latest: v%d.%d", b.Latest.GetMajor(), b.Latest.GetMinor())
+	}
+	if b.Oldest.GetMajor() != 0 || b.Oldest.GetMinor() != 9 {
+		t.Fatalf("unexpected oldest: v%d.%d", b.Oldest.GetMajor(), b.Oldest.GetMinor())
+	}
+
+	idx, err := loadIndex(dir)
+	if err != nil || idx == nil {
+		t.Fatalf("index was not persisted: %v", err)
+	}
+	if len(idx.Entries) != 3 || idx.Latest != "v1.0" {
+		t.Fatalf("unexpected index: %+v", idx)
+	}
+	for _, e := range idx.Entries {
+		if e.Trigger != TriggerMigration || e.SHA256 == "" || e.Size == 0 {
+			t.Errorf("unexpected entry: %+v", e)
+		}
+		if e.File == RegularFileName(0, 9) && e.Revision != 1 {
+			t.Errorf("expected revision 1 for v0.9, got %d", e.Revision)
+		}
+		if e.File == RegularFileName(1, 0) && e.Revision != 2 {
+			t.Errorf("expected revision 2 for v1.0, got %d", e.Revision)
+		}
+	}
+
+	chain, err := b.DeltaChain(0, 9)
+	if err != nil || len(chain) != 1 {
+		t.Fatalf("unexpected chain: %v, %v", chain, err)
+	}
+}
+
+func TestRecordAssignsMonotonicRevisions(t *testing.T) {
+	dir := t.TempDir()
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// minor 9 이후에도 자리올림 없이 증가
+	for i := 0; i < 11; i++ {
+		major, minor := b.NextVersion()
+		writeBundle(t, dir, RegularFileName(major, minor), `{}`)
+
+		e, err := b.Record(context.Background(), IndexEntry{
+			Version: Revision(major, minor),
+			Type:    TypeRegular,
+			File:    RegularFileName(major, minor),
+			Trigger: TriggerPolicy,
+		})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if e.Revision != int64(i+1) {
+			t.Fatalf("expected revision %d, got %d", i+1, e.Revision)
+		}
+		if b.GetEtag() != `"`+e.SHA256+`"` {
+			t.Fatalf("etag was not updated")
+		}
+	}
+	if b.Latest.GetMajor() != 0 || b.Latest.GetMinor() != 11 {
+		t.Fatalf("unexpected latest: v%d.%d", b.Latest.GetMajor(), b.Latest.GetMinor())
+	}
+
+	// 재시작 후에도 index.json에서 동일한 상태 복원
+	reloaded, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if reloaded.Latest.GetMinor() != 11 || len(reloaded.Entries()) != 11 {
+		t.Fatalf("index was not restored: v%d.%d, %d entries",
+			reloaded.Latest.GetMajor(), reloaded.Latest.GetMinor(), len(reloaded.Entries()))
+	}
+	if major, minor := reloaded.NextVersion(); major != 0 || minor != 12 {
+		t.Fatalf("unexpected next version: v%d.%d", major, minor)
+	}
+}
+
+func TestRecordRejectsDuplicateVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	unlock := b.LockPublish()
+	major, minor := b.NextVersion()
+	writeBundle(t, dir, RegularFileName(major, minor), `{"a":1}`)
+	entry := IndexEntry{Version: Revision(major, minor), Type: TypeRegular, File: RegularFileName(major, minor), Trigger: TriggerData}
+	if _, err := b.Record(context.Background(), entry); err != nil {
+		t.Fatalf("%v", err)
+	}
+	unlock()
+
+	// 같은 버전을 다시 기록하면 이전 기록(SHA, ETag)을 교체하지 않음
+	etag := b.GetEtag()
+	writeBundle(t, dir, RegularFileName(major, minor), `{"a":2}`)
+	if _, err := b.Record(context.Background(), entry); !errors.Is(err, ErrVersionExists) {
+		t.Fatalf("expected ErrVersionExists, got %v", err)
+	}
+	if b.GetEtag() != etag || len(b.Entries()) != 1 {
+		t.Fatalf("index must not change on duplicate record")
+	}
+	if major, minor := b.NextVersion(); major != 0 || minor != 2 {
+		t.Fatalf("unexpected next version: v%d.%d", major, minor)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, RegularFileName(0, 1), `{"a":1}`)
+	writeBundle(t, dir, RegularFileName(0, 2), `{"a":2}`)
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if _, err := b.Rollback(context.Background(), 0, 3); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("expected ErrVersionNotFound, got %v", err)
+	}
+
+	e, err := b.Rollback(context.Background(), 0, 1)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if e.Version != "v0.1" || b.Latest.GetMinor() != 1 {
+		t.Fatalf("unexpected latest after rollback: %s, v0.%d", e.Version, b.Latest.GetMinor())
+	}
+	if b.GetEtag() != `"`+e.SHA256+`"` {
+		t.Fatalf("etag must be updated with the index: %s", b.GetEtag())
+	}
+
+	// 이미 발급된 v0.2를 재사용하지 않음
+	if major, minor := b.NextVersion(); major != 0 || minor != 3 {
+		t.Fatalf("unexpected next version: v%d.%d", major, minor)
+	}
+
+	reloaded, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if reloaded.Latest.GetMinor() != 1 {
+		t.Fatalf("rollback was not persisted: v0.%d", reloaded.Latest.GetMinor())
+	}
+}
+
+func TestEntryAndInspect(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, RegularFileName(0, 1), `{"a":1}`)
+	writeBundle(t, dir, RegularFileName(0, 2), `{"a":2}`)
+	writeBundle(t, dir, DeltaFileName(0, 1, 0, 2), `{}`)
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	e, err := b.Entry("v0.2", TypeDelta, "")
+	if err != nil || e.File != DeltaFileName(0, 1, 0, 2) {
+		t.Fatalf("unexpected entry: %+v, %v", e, err)
+	}
+	if _, err := b.Entry("v0.3", TypeRegular, ""); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("expected ErrVersionNotFound, got %v", err)
+	}
+
+	manifest, files, err := Inspect(filepath.Join(dir, RegularFileName(0, 2)))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if manifest != nil || len(files) != 1 || files[0].Name != "data.json" || files[0].Size != 7 {
+		t.Fatalf("unexpected inspect result: %+v, %+v", manifest, files)
+	}
+}
+
+func TestWaitForChange(t *testing.T) {
+	dir := t.TempDir()
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	etag := b.GetEtag()
+
+	// etag가 바뀌지 않으면 ctx 만료까지 대기
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if b.WaitForChange(ctx, etag) {
+		t.Fatalf("expected timeout without a new bundle")
+	}
+
+	// 이미 다른 etag를 가진 경우 즉시 반환
+	if !b.WaitForChange(context.Background(), `"stale"`) {
+		t.Fatalf("expected immediate return for a stale etag")
+	}
+
+	done := make(chan bool)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- b.WaitForChange(ctx, etag)
+	}()
+
+	writeBundle(t, dir, RegularFileName(0, 1), `{}`)
+	if _, err := b.Record(context.Background(), IndexEntry{
+		Version: Revision(0, 1),
+		Type:    TypeRegular,
+		File:    RegularFileName(0, 1),
+		Trigger: TriggerData,
+	}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if !<-done {
+		t.Fatalf("waiter was not notified of the new bundle")
+	}
+}
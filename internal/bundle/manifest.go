@@ -0,0 +1,43 @@
+package bundle
+
+import "fmt"
+
+const (
+	ManifestFile = ".manifest"
+
+	TypeRegular = "regular"
+	TypeDelta   = "delta"
+)
+
+// OPA bundle .manifest
+// https://www.openpolicyagent.org/docs/latest/management-bundles/#bundle-file-format
+type Manifest struct {
+	Revision string         `json:"revision"`
+	Roots    *[]string      `json:"roots,omitempty"` // nil인 경우 OPA는 전체 data tree를 소유하는 것으로 처리
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+func Revision(major, minor int) string {
+	return fmt.Sprintf("v%d.%d", major, minor)
+}
+
+func (b *Bundle) NewManifest(revision string, bundleType string) *Manifest {
+	m := &Manifest{
+		Revision: revision,
+	}
+
+	if len(b.Roots) > 0 {
+		roots := make([]string, len(b.Roots))
+		copy(roots, b.Roots)
+		m.Roots = &roots
+	}
+
+	// delta bundle은 metadata에 type을 명시
+	if bundleType == TypeDelta {
+		m.Metadata = map[string]any{
+			"type": TypeDelta,
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,127 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// regular bundle 보존 정책
+// KeepLast, KeepDays 중 하나라도 만족하면 보존하며 둘 다 0이면 GC하지 않음
+type Retention struct {
+	KeepLast int      // 최근 N개 보존
+	KeepDays int      // D일 이내 생성된 bundle 보존
+	Pinned   []string // 항상 보존할 버전 (e.g. v1.3)
+}
+
+func (r Retention) Enabled() bool {
+	return r.KeepLast > 0 || r.KeepDays > 0
+}
+
+// 보존 정책에 따라 오래된 bundle을 삭제하고 삭제된(dryRun인 경우 삭제 대상) entry를 반환
+//   - 현재 Latest 버전은 항상 보존
+//   - 삭제된 regular 버전에서 시작하거나 끝나는 delta bundle도 함께 삭제
+//   - 디스크에 파일이 없는 entry는 index에서 제거
+func (b *Bundle) GC(ctx context.Context, now time.Time, dryRun bool) ([]IndexEntry, error) {
+	if !b.Retention.Enabled() {
+		return nil, nil
+	}
+
+	b.mu.Lock()
+	if err := b.reloadIndex(); err != nil {
+		b.mu.Unlock()
+		return nil, err
+	}
+
+	keep := b.retainedVersions(now)
+
+	idx := &Index{Latest: b.index.Latest}
+	var removed []IndexEntry
+	for _, e := range b.index.Entries {
+		var drop bool
+		switch e.Type {
+		case TypeRegular:
+			drop = !keep[e.Version]
+		case TypeDelta:
+			drop = !keep[e.From] || !keep[e.Version]
+		}
+		if _, err := os.Stat(filepath.Join(b.DirPath, e.File)); errors.Is(err, os.ErrNotExist) {
+			drop = true
+		}
+
+		if drop {
+			removed = append(removed, e)
+			continue
+		}
+		idx.Entries = append(idx.Entries, e)
+	}
+
+	if dryRun || len(removed) == 0 {
+		b.mu.Unlock()
+		return removed, nil
+	}
+
+	// index를 먼저 갱신해 삭제될 파일이 더 이상 서빙되지 않도록 함
+	if err := writeIndex(ctx, b.DirPath, idx); err != nil {
+		b.mu.Unlock()
+		return nil, err
+	}
+	b.index = idx
+	b.syncVersions()
+	b.mu.Unlock()
+
+	var errs []error
+	for _, e := range removed {
+		if err := os.Remove(filepath.Join(b.DirPath, e.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", e.File, err))
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// 보존 대상 regular 버전 (caller가 b.mu를 잡고 있어야 함)
+func (b *Bundle) retainedVersions(now time.Time) map[string]bool {
+	keep := map[string]bool{b.index.Latest: true}
+	for _, v := range b.Retention.Pinned {
+		if major, minor, err := ParseVersion(v); err == nil {
+			keep[Revision(major, minor)] = true
+		}
+	}
+
+	var regulars []IndexEntry
+	for _, e := range b.index.Entries {
+		if e.Type == TypeRegular {
+			regulars = append(regulars, e)
+		}
+	}
+	sort.Slice(regulars, func(i, j int) bool {
+		return regulars[i].Revision > regulars[j].Revision
+	})
+
+	cutoff := now.AddDate(0, 0, -b.Retention.KeepDays)
+	for i, e := range regulars {
+		if i < b.Retention.KeepLast {
+			keep[e.Version] = true
+		}
+		if b.Retention.KeepDays > 0 && e.CreatedAt.After(cutoff) {
+			keep[e.Version] = true
+		}
+	}
+	return keep
+}
+
+// 다른 프로세스(gc 커맨드 등)가 갱신한 index.json 반영 (caller가 b.mu를 잡고 있어야 함)
+func (b *Bundle) reloadIndex() error {
+	idx, err := loadIndex(b.DirPath)
+	if err != nil {
+		return err
+	}
+	if idx != nil {
+		b.index = idx
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package bundle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGC(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	b, err := NewBundle(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// v0.1 ~ v0.6, 하루 간격 생성
+	for i := 1; i <= 6; i++ {
+		writeBundle(t, dir, RegularFileName(0, i), `{}`)
+		if _, err := b.Record(context.Background(), IndexEntry{
+			Version:   Revision(0, i),
+			Type:      TypeRegular,
+			File:      RegularFileName(0, i),
+			CreatedAt: now.AddDate(0, 0, i-6),
+		}); err != nil {
+			t.Fatalf("%v", err)
+		}
+		if i == 1 {
+			continue
+		}
+		writeBundle(t, dir, DeltaFileName(0, i-1, 0, i), `{}`)
+		if _, err := b.Record(context.Background(), IndexEntry{
+			Version: Revision(0, i),
+			Type:    TypeDelta,
+			From:    Revision(0, i-1),
+			File:    DeltaFileName(0, i-1, 0, i),
+		}); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	b.Retention = Retention{KeepLast: 2, KeepDays: 3, Pinned: []string{"0.1"}}
+
+	removed, err := b.GC(context.Background(), now, true)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(removed) == 0 {
+		t.Fatalf("expected bundles to remove")
+	}
+	for _, e := range removed {
+		if _, err := os.Stat(filepath.Join(dir, e.File)); err != nil {
+			t.Fatalf("dry run removed %s", e.File)
+		}
+	}
+
+	removed, err = b.GC(context.Background(), now, false)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// v0.1(pinned), v0.4(3일 이내), v0.5, v0.6 보존
+	want := map[string]bool{
+		RegularFileName(0, 1):     true,
+		RegularFileName(0, 4):     true,
+		RegularFileName(0, 5):     true,
+		RegularFileName(0, 6):     true,
+		DeltaFileName(0, 4, 0, 5): true,
+		DeltaFileName(0, 5, 0, 6): true,
+	}
+	entries := b.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("unexpected entries after gc: %+v", entries)
+	}
+	for _, e := range entries {
+		if !want[e.File] {
+			t.Errorf("unexpected entry kept: %s", e.File)
+		}
+	}
+	for _, e := range removed {
+		if want[e.File] {
+			t.Errorf("retained bundle removed: %s", e.File)
+		}
+		if _, err := os.Stat(filepath.Join(dir, e.File)); !os.IsNotExist(err) {
+			t.Errorf("file was not removed: %s", e.File)
+		}
+	}
+
+	if b.Oldest.GetMinor() != 1 || b.Latest.GetMinor() != 6 {
+		t.Fatalf("unexpected versions: oldest v0.%d, latest v0.%d", b.Oldest.GetMinor(), b.Latest.GetMinor())
+	}
+
+	// 디스크에서 사라진 bundle은 index에서도 제거
+	os.Remove(filepath.Join(dir, RegularFileName(0, 1)))
+	if _, err := b.GC(context.Background(), now, false); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if b.Oldest.GetMinor() != 4 {
+		t.Fatalf("oldest was not updated: v0.%d", b.Oldest.GetMinor())
+	}
+}
@@ -0,0 +1,488 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OPA bundle signing
+// https://www.openpolicyagent.org/docs/latest/management-bundles/#signing
+const (
+	SignaturesFile = ".signatures.json"
+
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+
+	hashAlgorithm = "SHA-256"
+)
+
+var ErrVerifyBundle = errors.New("bundle verification failed")
+
+type FileHash struct {
+	Name      string `json:"name"`
+	Hash      string `json:"hash"`
+	Algorithm string `json:"algorithm"`
+}
+
+type Signatures struct {
+	Signatures []string `json:"signatures"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type jwtPayload struct {
+	Files []FileHash `json:"files"`
+	KeyID string     `json:"keyid,omitempty"`
+}
+
+type Signer struct {
+	Algorithm string
+	KeyID     string
+	key       any // []byte(HS256) | *rsa.PrivateKey | *ecdsa.PrivateKey
+}
+
+type Verifier struct {
+	Algorithm string
+	KeyID     string
+	key       any // []byte(HS256) | *rsa.PublicKey | *ecdsa.PublicKey
+}
+
+// keyFile: HS256인 경우 secret 파일, RS256/ES256인 경우 PEM 개인키 파일
+func NewSigner(alg, keyID, keyFile string) (*Signer, error) {
+	raw, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	var key any
+	switch alg {
+	case HS256:
+		key = bytes.TrimSpace(raw)
+	case RS256, ES256:
+		key, err = parsePrivateKey(raw)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+
+	if err := checkKeyType(alg, key); err != nil {
+		return nil, err
+	}
+
+	return &Signer{Algorithm: alg, KeyID: keyID, key: key}, nil
+}
+
+// keyFile: HS256인 경우 secret 파일, RS256/ES256인 경우 PEM 공개키(또는 인증서) 파일
+func NewVerifier(alg, keyID, keyFile string) (*Verifier, error) {
+	raw, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification key: %w", err)
+	}
+
+	var key any
+	switch alg {
+	case HS256:
+		key = bytes.TrimSpace(raw)
+	case RS256, ES256:
+		key, err = parsePublicKey(raw)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+
+	if err := checkKeyType(alg, key); err != nil {
+		return nil, err
+	}
+
+	return &Verifier{Algorithm: alg, KeyID: keyID, key: key}, nil
+}
+
+// .signatures.json 생성
+func (s *Signer) Sign(files []File) ([]byte, error) {
+	hashes, err := hashFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := json.Marshal(jwtHeader{Alg: s.Algorithm, Typ: "JWT", Kid: s.KeyID})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(jwtPayload{Files: hashes, KeyID: s.KeyID})
+	if err != nil {
+		return nil, err
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+	sig, err := s.sign([]byte(signingInput))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign bundle: %w", err)
+	}
+
+	return json.Marshal(Signatures{
+		Signatures: []string{signingInput + "." + encodeSegment(sig)},
+	})
+}
+
+func (s *Signer) sign(input []byte) ([]byte, error) {
+	switch key := s.key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256(input)
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(input)
+		r, sv, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS ES256: r || s (각 32byte)
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		sv.FillBytes(sig[32:])
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", key)
+	}
+}
+
+// .signatures.json의 서명을 검증하고 bundle 내 모든 파일의 해시를 비교
+func (v *Verifier) Verify(files []File) error {
+	var (
+		sigFile *File
+		others  []File
+	)
+	for i := range files {
+		if files[i].Name == SignaturesFile {
+			sigFile = &files[i]
+			continue
+		}
+		others = append(others, files[i])
+	}
+	if sigFile == nil {
+		return fmt.Errorf("%w: %s not found", ErrVerifyBundle, SignaturesFile)
+	}
+
+	var sigs Signatures
+	if err := json.Unmarshal(sigFile.Data, &sigs); err != nil {
+		return fmt.Errorf("%w: invalid %s: %v", ErrVerifyBundle, SignaturesFile, err)
+	}
+	if len(sigs.Signatures) != 1 {
+		return fmt.Errorf("%w: expected exactly one signature, got %d", ErrVerifyBundle, len(sigs.Signatures))
+	}
+
+	payload, err := v.verifyToken(sigs.Signatures[0])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrVerifyBundle, err)
+	}
+
+	signed := make(map[string]FileHash, len(payload.Files))
+	for _, f := range payload.Files {
+		signed[f.Name] = f
+	}
+
+	hashes, err := hashFiles(others)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrVerifyBundle, err)
+	}
+
+	for _, h := range hashes {
+		s, ok := signed[h.Name]
+		if !ok {
+			return fmt.Errorf("%w: file %s is not signed", ErrVerifyBundle, h.Name)
+		}
+		if !strings.EqualFold(s.Algorithm, hashAlgorithm) || s.Hash != h.Hash {
+			return fmt.Errorf("%w: hash mismatch for %s", ErrVerifyBundle, h.Name)
+		}
+		delete(signed, h.Name)
+	}
+	if len(signed) > 0 {
+		missing := make([]string, 0, len(signed))
+		for name := range signed {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("%w: signed files missing from bundle: %s", ErrVerifyBundle, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyToken(token string) (*jwtPayload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed signature token")
+	}
+
+	rawHeader, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	if header.Alg != v.Algorithm {
+		return nil, fmt.Errorf("unexpected algorithm: %s", header.Alg)
+	}
+
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+	if err := v.verify([]byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	rawPayload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+	var payload jwtPayload
+	if err := json.Unmarshal(rawPayload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+
+	if v.KeyID != "" {
+		kid := payload.KeyID
+		if kid == "" {
+			kid = header.Kid
+		}
+		if kid != v.KeyID {
+			return nil, fmt.Errorf("unexpected key id: %s", kid)
+		}
+	}
+
+	return &payload, nil
+}
+
+func (v *Verifier) verify(input, sig []byte) error {
+	digest := sha256.Sum256(input)
+
+	switch key := v.key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(input)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return fmt.Errorf("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+	case *ecdsa.PublicKey:
+		if len(sig) != 64 {
+			return fmt.Errorf("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type: %T", key)
+	}
+	return nil
+}
+
+// 파일별 SHA-256 해시 (.signatures.json 제외, 이름순 정렬)
+func hashFiles(files []File) ([]FileHash, error) {
+	hashes := make([]FileHash, 0, len(files))
+	for _, f := range files {
+		if f.Name == SignaturesFile {
+			continue
+		}
+		h, err := HashFile(f.Name, f.Data)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, FileHash{Name: f.Name, Hash: h, Algorithm: hashAlgorithm})
+	}
+
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Name < hashes[j].Name })
+	return hashes, nil
+}
+
+// data.json, .manifest 같은 구조화 문서는 공백/키 순서에 영향받지 않도록
+// OPA와 동일하게 key를 정렬한 JSON으로 해시, 그 외 파일은 원본 바이트를 해시
+func HashFile(name string, data []byte) (string, error) {
+	h := sha256.New()
+
+	if isStructuredDoc(name) {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if err := writeCanonical(h, v); err != nil {
+			return "", err
+		}
+	} else {
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func isStructuredDoc(name string) bool {
+	base := filepath.Base(name)
+	return base == "data.json" || base == ManifestFile
+}
+
+func writeCanonical(w io.Writer, v any) error {
+	switch x := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		w.Write([]byte("{"))
+		for i, k := range keys {
+			if i > 0 {
+				w.Write([]byte(","))
+			}
+			if err := writePrimitive(w, k); err != nil {
+				return err
+			}
+			w.Write([]byte(":"))
+			if err := writeCanonical(w, x[k]); err != nil {
+				return err
+			}
+		}
+		w.Write([]byte("}"))
+	case []any:
+		w.Write([]byte("["))
+		for i, e := range x {
+			if i > 0 {
+				w.Write([]byte(","))
+			}
+			if err := writeCanonical(w, e); err != nil {
+				return err
+			}
+		}
+		w.Write([]byte("]"))
+	default:
+		return writePrimitive(w, x)
+	}
+	return nil
+}
+
+func writePrimitive(w io.Writer, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(bytes.TrimRight(buf.Bytes(), "\n"))
+	return err
+}
+
+func parsePrivateKey(raw []byte) (any, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM private key")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+}
+
+func parsePublicKey(raw []byte) (any, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM public key")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+}
+
+func checkKeyType(alg string, key any) error {
+	ok := false
+	switch alg {
+	case HS256:
+		k, isBytes := key.([]byte)
+		ok = isBytes && len(k) > 0
+	case RS256:
+		switch key.(type) {
+		case *rsa.PrivateKey, *rsa.PublicKey:
+			ok = true
+		}
+	case ES256:
+		switch k := key.(type) {
+		case *ecdsa.PrivateKey:
+			ok = k.Curve == elliptic.P256()
+		case *ecdsa.PublicKey:
+			ok = k.Curve == elliptic.P256()
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("key type %T does not match algorithm %s", key, alg)
+	}
+	return nil
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,166 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rsaPriv, rsaPub := writeKeyPair(t, dir, "rsa", rsaKey, &rsaKey.PublicKey)
+	ecPriv, ecPub := writeKeyPair(t, dir, "ec", ecKey, &ecKey.PublicKey)
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	cases := []struct {
+		alg     string
+		private string
+		public  string
+	}{
+		{HS256, secret, secret},
+		{RS256, rsaPriv, rsaPub},
+		{ES256, ecPriv, ecPub},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.alg, func(t *testing.T) {
+			signer, err := NewSigner(tc.alg, "test", tc.private)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			verifier, err := NewVerifier(tc.alg, "test", tc.public)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			files := testFiles()
+			sig, err := signer.Sign(files)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			signed := append(files, File{Name: SignaturesFile, Data: sig})
+
+			// tar.gz 왕복 후에도 검증 성공
+			buf := new(bytes.Buffer)
+			if err := WriteTarGz(buf, signed); err != nil {
+				t.Fatalf("%v", err)
+			}
+			read, err := ReadTarGz(buf)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if err := verifier.Verify(read); err != nil {
+				t.Fatalf("expected valid signature: %v", err)
+			}
+
+			// 공백/키 순서만 다른 data.json은 동일한 해시
+			reformatted := cloneFiles(signed)
+			reformatted[1].Data = []byte(`{"policies": [], "default_effect": "deny"}`)
+			if err := verifier.Verify(reformatted); err != nil {
+				t.Fatalf("expected canonical json hash to match: %v", err)
+			}
+
+			tampered := cloneFiles(signed)
+			tampered[1].Data = []byte(`{"default_effect":"allow","policies":[]}`)
+			if err := verifier.Verify(tampered); !errors.Is(err, ErrVerifyBundle) {
+				t.Fatalf("expected tampered data.json to be rejected, got %v", err)
+			}
+
+			added := append(cloneFiles(signed), File{Name: "extra.rego", Data: []byte("package extra")})
+			if err := verifier.Verify(added); !errors.Is(err, ErrVerifyBundle) {
+				t.Fatalf("expected unsigned file to be rejected, got %v", err)
+			}
+
+			if err := verifier.Verify(files); !errors.Is(err, ErrVerifyBundle) {
+				t.Fatalf("expected missing signature to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWrongKey(t *testing.T) {
+	dir := t.TempDir()
+
+	k1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	k2, _ := rsa.GenerateKey(rand.Reader, 2048)
+	priv, _ := writeKeyPair(t, dir, "k1", k1, &k1.PublicKey)
+	_, otherPub := writeKeyPair(t, dir, "k2", k2, &k2.PublicKey)
+
+	signer, err := NewSigner(RS256, "", priv)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	verifier, err := NewVerifier(RS256, "", otherPub)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	files := testFiles()
+	sig, err := signer.Sign(files)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	err = verifier.Verify(append(files, File{Name: SignaturesFile, Data: sig}))
+	if !errors.Is(err, ErrVerifyBundle) {
+		t.Fatalf("expected signature from another key to be rejected, got %v", err)
+	}
+}
+
+func testFiles() []File {
+	return []File{
+		{Name: ManifestFile, Data: []byte(`{"revision":"v0.1"}`)},
+		{Name: "data.json", Data: []byte("{\n  \"default_effect\": \"deny\",\n  \"policies\": []\n}\n")},
+		{Name: "policy.rego", Data: []byte("package casb\n")},
+	}
+}
+
+func cloneFiles(files []File) []File {
+	out := make([]File, len(files))
+	copy(out, files)
+	return out
+}
+
+func writeKeyPair(t *testing.T, dir, name string, priv, pub any) (string, string) {
+	t.Helper()
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	privPath := filepath.Join(dir, name+".pem")
+	pubPath := filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatalf("%v", err)
+	}
+	return privPath, pubPath
+}
@@ -1,10 +1,16 @@
 package clients
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"path/filepath"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/jjhwan-h/bundle-server/config"
 	"github.com/jjhwan-h/bundle-server/internal/bundle"
@@ -13,38 +19,89 @@ import (
 )
 
 type Client struct { // 이벤트발생 시 알림보낼 client
-	data   map[string][]string
-	Bundle map[string]*bundle.Bundle
-	mu     sync.Mutex
+	registry Registry          // 등록된 client (file | mysql | memory)
+	secrets  map[string][]byte // client 주소 => webhook 서명 secret
+	Outbox   *Outbox           // 전송 대기/실패한 webhook
+	health   *Prober           // nil이면 health check 비활성화
+	Bundle   map[string]*bundle.Bundle
+
+	httpClient  *http.Client  // webhook 전용 (http.DefaultClient는 timeout 없음)
+	timeout     time.Duration // 요청 1건의 timeout
+	concurrency int           // 동시 전송 수
 }
 
-func NewClient(logger *zap.Logger, clients map[string][]string) *Client {
+// 초기화 실패 시 서버를 시작하지 않도록 error 반환
+func NewClient(logger *zap.Logger, clients map[string][]string) (*Client, error) {
 	Client := &Client{
-		data:   make(map[string][]string),
 		Bundle: make(map[string]*bundle.Bundle),
 	}
 
-	Client.mu.Lock()
-	defer Client.mu.Unlock()
+	// 등록된 client는 저장소에서 유지, config.yaml의 client는 service별 최초 1회만 등록
+	registry, err := NewRegistry(config.Cfg.Clients.Registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open client registry: %w", err)
+	}
+	if err := seedRegistry(context.Background(), registry, clients, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to register clients from config: %w", err)
+	}
+	Client.registry = registry
 
-	if len(clients) > 0 {
-		Client.data = clients
+	secrets, err := LoadSecrets(config.Cfg.Clients.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook secrets: %w", err)
+	}
+	Client.secrets = secrets
+
+	delivery := config.Cfg.Clients.Delivery
+	outbox, err := NewOutbox(filepath.Join(config.Cfg.OpaDataPath, "outbox"), Backoff{
+		Initial: durationOr(delivery.InitialBackoff, time.Second, time.Second),
+		Max:     durationOr(delivery.MaxBackoff, time.Second, 5*time.Minute),
+		MaxAge:  durationOr(delivery.MaxAge, time.Minute, 24*time.Hour),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook outbox: %w", err)
+	}
+	Client.Outbox = outbox
+	Client.timeout = durationOr(delivery.Timeout, time.Second, 5*time.Second)
+	Client.concurrency = delivery.Concurrency
+	if Client.concurrency <= 0 {
+		Client.concurrency = 32
+	}
+	Client.httpClient = newHookHTTPClient(Client.timeout, Client.concurrency)
+
+	if hc := config.Cfg.Clients.Health; hc.Interval > 0 {
+		timeout := durationOr(hc.Timeout, time.Second, 3*time.Second)
+		Client.health = NewProber(hc.Path, hc.FailureThreshold, timeout, newHookHTTPClient(timeout, Client.concurrency))
 	}
 
 	for k := range clients {
-		Client.Bundle[k] = bundle.NewBundle(
+		b, err := bundle.NewBundle(
 			fmt.Sprintf("%s/%s", config.Cfg.OpaDataPath, k),
 		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bundle index of %s: %w", k, err)
+		}
+		Client.Bundle[k] = b
+		Client.Bundle[k].Roots = config.Cfg.Bundle.Service[k].Roots
+		Client.Bundle[k].Retention = NewRetention(config.Cfg.Bundle.Service[k].Retention)
 
-		minor := Client.Bundle[k].Latest.Minor
-		major := Client.Bundle[k].Latest.Major
+		if signing := config.Cfg.Bundle.Service[k].Signing; signing.PrivateKey != "" {
+			signer, err := bundle.NewSigner(signing.Algorithm, signing.KeyID, signing.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load bundle signing key of %s: %w", k, err)
+			}
+			Client.Bundle[k].Signer = signer
+			logger.Info("bundle signing enabled", zap.String("service", k), zap.String("algorithm", signing.Algorithm))
+		}
+
+		minor := Client.Bundle[k].Latest.GetMinor()
+		major := Client.Bundle[k].Latest.GetMajor()
 		if minor == 0 && major == 0 {
 			logger.Info("bundle version is starting from v0.1", zap.String("service", k))
 		} else {
 			etag, err := Client.Bundle[k].ETagFromFile()
 			if err != nil {
-				logger.Error("failed to hash latest bundle", zap.String("version", fmt.Sprintf("v%d.%d", major, minor)), zap.Error(err))
-				return nil
+				return nil, fmt.Errorf("failed to hash latest bundle of %s (v%d.%d): %w", k, major, minor, err)
 			}
 
 			logger.Info("latest bundle", zap.String("version", fmt.Sprintf("v%d.%d", major, minor)))
@@ -56,120 +113,194 @@ func NewClient(logger *zap.Logger, clients map[string][]string) *Client {
 		}
 	}
 
-	return Client
+	return Client, nil
 }
 
-func (b *Client) New(key string) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	b.data[key] = []string{}
+// service별 등록된 client
+func (b *Client) GetAll(ctx context.Context) (map[string][]Record, error) {
+	return b.registry.List(ctx)
 }
 
-func (b *Client) Add(key, val string) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	b.data[key] = append(b.data[key], val)
+func (b *Client) Get(ctx context.Context, service string) ([]Record, error) {
+	return b.registry.Get(ctx, service)
 }
 
-func (b *Client) GetAll() map[string][]string {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	return b.data
+// key는 client id 또는 url
+func (b *Client) Delete(ctx context.Context, service, key string) error {
+	return b.registry.Delete(ctx, service, key)
 }
 
-func (b *Client) Get(service string) []string {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	return b.data[service]
+func (b *Client) DeleteAll(ctx context.Context, service string) error {
+	return b.registry.DeleteAll(ctx, service)
 }
 
-func (b *Client) Delete(service, value string) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	clients := b.data[service]
+// service의 모든 client에 event를 동시에 전송하고 client별 결과를 반환
+// outbox에 먼저 기록하므로 실패한 전송은 RunDeliveries에서 backoff 후 재시도
+func (b *Client) Hook(ctx context.Context, logger *zap.Logger, path string, event HookEvent) ([]DeliveryResult, error) {
 	var (
-		tmp   []string
-		found bool
+		deliveries    []Delivery
+		failedTargets []string
 	)
+	now := time.Now()
 
-	for _, client := range clients {
-		if client == value {
-			found = true
+	records, err := b.Get(ctx, event.Service)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", appErr.ErrSendEventNotification, err)
+	}
+	for _, rec := range records {
+		if b.health != nil && b.health.skip(rec.Service, rec.ID) {
+			logger.Warn("client quarantined, skipping event notification",
+				zap.String("service", rec.Service),
+				zap.String("client", rec.ID),
+				zap.String("version", event.Version),
+			)
+			continue
+		}
+		p := rec.hookURL(path)
+		d, err := b.Outbox.enqueue(rec, p, event, now, true)
+		if err != nil {
+			failedTargets = append(failedTargets, fmt.Sprintf("%s (%v)", p, err))
 			continue
 		}
-		tmp = append(tmp, client)
+		deliveries = append(deliveries, *d)
 	}
 
-	if !found {
-		return fmt.Errorf("client '%s' not found for service '%s'", value, service)
+	results := b.Outbox.dispatch(ctx, logger, deliveries, b.deliver, b.concurrency)
+	for _, r := range results {
+		if !r.ok() {
+			failedTargets = append(failedTargets, fmt.Sprintf("%s (%s)", r.URL, attemptError(r.DeliveryAttempt)))
+		}
 	}
 
-	b.data[service] = tmp
-	return nil
+	if len(failedTargets) != 0 {
+		return results, fmt.Errorf("%w: %s", appErr.ErrSendEventNotification, strings.Join(failedTargets, ", "))
+	}
+	return results, nil
 }
 
-func (b *Client) DeleteAll(service string) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	b.data[service] = []string{}
+// delivery 1회 전송 후 client의 last_success/last_failure 기록
+func (b *Client) deliver(ctx context.Context, d Delivery) DeliveryAttempt {
+	attempt := b.send(ctx, d)
+	errMsg := ""
+	if !attempt.ok() {
+		errMsg = attemptError(attempt)
+	}
+	if d.ClientID != "" {
+		// 전송 결과 기록 실패(e.g. 삭제된 client)는 재시도에 영향 없음
+		_ = b.registry.RecordResult(context.WithoutCancel(ctx), d.Service, d.ClientID, attempt.At, errMsg)
+	}
+	return attempt
 }
 
-func (b *Client) IsDuplicate(key, val string) bool {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	for _, v := range b.data[key] {
-		if v == val {
-			return true
+// secret은 client 등록 정보, 없으면 config clients.secrets
+// 저장소 조회에 실패하면 서명 없이 보내지 않도록 error 반환
+func (b *Client) secret(ctx context.Context, d Delivery) ([]byte, error) {
+	if d.ClientID != "" {
+		records, err := b.registry.Get(ctx, d.Service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up webhook secret: %w", err)
+		}
+		for _, r := range records {
+			if r.ID == d.ClientID && r.Secret != "" {
+				return []byte(r.Secret), nil
+			}
 		}
 	}
-	return false
+	return b.secrets[trimURL(d.Target)], nil
 }
 
-func (b *Client) Hook(path string, service string) error {
-	var (
-		err           error
-		failedTargets []string
-	)
-	clients := b.Get(service)
+// secret이 있으면 X-Signature 서명
+func (b *Client) send(ctx context.Context, d Delivery) DeliveryAttempt {
+	start := time.Now()
+	attempt := DeliveryAttempt{At: start.UTC()}
+	defer func() { attempt.DurationMs = time.Since(start).Milliseconds() }()
 
-	for _, addr := range clients {
-		p := fmt.Sprintf("%s/%s", addr, path)
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
 
-		req, err := http.NewRequest("POST", p, nil)
-		if err != nil {
-			failedTargets = append(failedTargets, p)
-			continue
-		}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
 
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil || resp.StatusCode >= 400 {
-			failedTargets = append(failedTargets, p)
-			continue
-		}
-		defer resp.Body.Close()
+	method := d.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, d.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, d.Event.ID)
+	secret, err := b.secret(ctx, d)
+	if err != nil {
+		// 실패한 전송으로 기록 (outbox에서 재시도)
+		attempt.Error = err.Error()
+		return attempt
+	}
+	if len(secret) > 0 {
+		req.Header.Set(SignatureHeader, SignPayload(secret, body, start))
 	}
 
-	if len(failedTargets) != 0 {
-		err = fmt.Errorf("%w: %s", appErr.ErrSendEventNotification, strings.Join(failedTargets, ", "))
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
 	}
-	return err
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // keep-alive 재사용
+
+	attempt.StatusCode = resp.StatusCode
+	return attempt
 }
 
-func (b *Client) AddHookClient(clients []string, service string) error {
-	for _, client := range clients {
-		if b.IsDuplicate(service, client) {
-			return fmt.Errorf("%w : %s", appErr.ErrAlreadyRegistered, client)
+// outbox의 pending delivery 전송 worker
+// quarantine된 client의 재시도는 회복 시까지 보류 (회복 시 notifyLatest가 최신 알림으로 대체)
+func (b *Client) RunDeliveries(ctx context.Context, logger *zap.Logger) {
+	b.Outbox.Run(ctx, logger, b.deliver, b.concurrency, b.quarantined)
+}
+
+func (b *Client) quarantined(d Delivery) bool {
+	return b.health != nil && d.ClientID != "" && b.health.skip(d.Service, d.ClientID)
+}
+
+func newHookHTTPClient(timeout time.Duration, concurrency int) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = concurrency
+	transport.MaxIdleConnsPerHost = 2
+	transport.ResponseHeaderTimeout = timeout
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		// webhook은 redirect를 따라가지 않음 (서명된 요청이 다른 주소로 전달되는 것 방지)
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// 등록 요청 검사(id 생략 시 생성) 후 저장, 등록된 client 반환
+func (b *Client) AddHookClient(ctx context.Context, records []Record, service string) ([]Record, error) {
+	now := time.Now()
+	for i := range records {
+		if err := records[i].normalize(service, now); err != nil {
+			return nil, err
 		}
 	}
+	if err := b.registry.Add(ctx, records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
 
-	for _, client := range clients {
-		b.Add(service, client)
+// config 값(n * unit), 0 이하이면 기본값
+func durationOr(n int, unit, def time.Duration) time.Duration {
+	if n <= 0 {
+		return def
 	}
-	return nil
+	return time.Duration(n) * unit
 }
@@ -0,0 +1,116 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+	"go.uber.org/zap"
+)
+
+func TestHookFanOut(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer slow.Close()
+
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer hung.Close()
+	defer close(release)
+
+	o, err := NewOutbox(t.TempDir(), Backoff{Initial: time.Second, Max: time.Second, MaxAge: time.Minute})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	targets := []string{hung.URL}
+	for i := 0; i < 20; i++ {
+		targets = append(targets, fmt.Sprintf("%s/opa-%d", slow.URL, i))
+	}
+	timeout := 500 * time.Millisecond
+	registry := NewMemoryRegistry()
+	for i, target := range targets {
+		if err := registry.Add(context.Background(), []Record{{ID: fmt.Sprintf("opa-%d", i), Service: "casb", URL: target}}); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	b := &Client{
+		registry:    registry,
+		Outbox:      o,
+		httpClient:  newHookHTTPClient(timeout, 32),
+		timeout:     timeout,
+		concurrency: 32,
+	}
+
+	start := time.Now()
+	results, err := b.Hook(context.Background(), zap.NewNop(), "hooks/bundle-update", NewHookEvent("casb", "regular", 0, 1, `"x"`, start))
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, appErr.ErrSendEventNotification) {
+		t.Fatalf("expected ErrSendEventNotification for hung client, got %v", err)
+	}
+	if elapsed > 2*timeout {
+		t.Fatalf("fan-out took %s, want about one timeout (%s)", elapsed, timeout)
+	}
+	if len(results) != len(targets) {
+		t.Fatalf("expected %d results, got %d", len(targets), len(results))
+	}
+	if results[0].Status != DeliveryPending || results[0].Error == "" {
+		t.Fatalf("hung client must be retried: %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if r.Status != DeliveryDelivered || r.StatusCode != http.StatusNoContent {
+			t.Fatalf("unexpected result: %+v", r)
+		}
+	}
+
+	// 실패한 전송만 outbox에 남음
+	if pending := o.List("casb", DeliveryPending); len(pending) != 1 || pending[0].Target != hung.URL {
+		t.Fatalf("unexpected outbox: %+v", pending)
+	}
+}
+
+// 저장소 조회 실패
+type failingRegistry struct {
+	Registry
+}
+
+func (failingRegistry) Get(ctx context.Context, service string) ([]Record, error) {
+	return nil, errors.New("registry unavailable")
+}
+
+func TestSendWithoutSecretLookup(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	b := &Client{
+		registry:   failingRegistry{NewMemoryRegistry()},
+		httpClient: newHookHTTPClient(time.Second, 1),
+		timeout:    time.Second,
+	}
+	d := Delivery{Service: "casb", ClientID: "opa-1", Target: srv.URL, URL: srv.URL, Event: NewHookEvent("casb", "regular", 0, 1, `"x"`, time.Now())}
+
+	// secret을 확인할 수 없으면 서명 없이 보내지 않고 실패로 기록
+	attempt := b.deliver(context.Background(), d)
+	if attempt.ok() || attempt.Error == "" {
+		t.Fatalf("attempt must fail when the secret lookup fails: %+v", attempt)
+	}
+	if requests != 0 {
+		t.Fatalf("webhook must not be sent without signature, got %d requests", requests)
+	}
+}
@@ -0,0 +1,45 @@
+package clients
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/config"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"go.uber.org/zap"
+)
+
+func NewRetention(cfg config.RetentionConfig) bundle.Retention {
+	return bundle.Retention{
+		KeepLast: cfg.KeepLast,
+		KeepDays: cfg.KeepDays,
+		Pinned:   cfg.Pinned,
+	}
+}
+
+// interval마다 모든 service의 bundle을 retention에 따라 정리
+func (b *Client) RunGC(ctx context.Context, logger *zap.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for service, bd := range b.Bundle {
+				removed, err := bd.GC(ctx, now, false)
+				if err != nil {
+					logger.Error("failed to collect bundles", zap.String("service", service), zap.Error(err))
+				}
+				for _, e := range removed {
+					logger.Info("bundle removed by retention policy",
+						zap.String("service", service),
+						zap.String("file", e.File),
+						zap.String("version", e.Version),
+					)
+				}
+			}
+		}
+	}
+}
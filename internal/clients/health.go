@@ -0,0 +1,261 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"go.uber.org/zap"
+)
+
+const (
+	HealthHealthy     = "healthy"
+	HealthUnhealthy   = "unhealthy"   // 연속 실패 횟수가 threshold 미만
+	HealthQuarantined = "quarantined" // webhook 전송 대상에서 제외
+
+	DefaultHealthPath = "health" // OPA GET /health
+)
+
+type HealthStatus struct {
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastProbe           time.Time `json:"last_probe"`
+	LastError           string    `json:"last_error,omitempty"`
+	Since               time.Time `json:"since"`                         // state 변경 시각
+	Missed              bool      `json:"missed_notification,omitempty"` // quarantine 중 전송하지 않은 알림 존재
+}
+
+// client health check 결과 (인스턴스별 메모리)
+type Prober struct {
+	path       string
+	threshold  int // 연속 실패 시 quarantine
+	httpClient *http.Client
+	timeout    time.Duration
+	status     map[string]*HealthStatus
+	mu         sync.Mutex
+}
+
+func NewProber(path string, threshold int, timeout time.Duration, httpClient *http.Client) *Prober {
+	if path == "" {
+		path = DefaultHealthPath
+	}
+	if threshold <= 0 {
+		threshold = 3
+	}
+	return &Prober{
+		path:       path,
+		threshold:  threshold,
+		httpClient: httpClient,
+		timeout:    timeout,
+		status:     make(map[string]*HealthStatus),
+	}
+}
+
+func healthKey(service, id string) string {
+	return service + "\x00" + id
+}
+
+func (p *Prober) Status(service, id string) (HealthStatus, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st, ok := p.status[healthKey(service, id)]
+	if !ok {
+		return HealthStatus{}, false
+	}
+	return *st, true
+}
+
+// quarantine된 client이면 놓친 알림으로 표시하고 true
+func (p *Prober) skip(service, id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st, ok := p.status[healthKey(service, id)]
+	if !ok || st.State != HealthQuarantined {
+		return false
+	}
+	st.Missed = true
+	return true
+}
+
+// probe 결과 반영 (errMsg가 비어있으면 성공)
+// quarantine에서 회복되었고 놓친 알림이 있으면 recovered
+func (p *Prober) update(service, id string, at time.Time, errMsg string) (st HealthStatus, changed, recovered bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := healthKey(service, id)
+	cur, ok := p.status[key]
+	if !ok {
+		cur = &HealthStatus{State: HealthHealthy, Since: at}
+		p.status[key] = cur
+	}
+	prev := cur.State
+
+	cur.LastProbe = at
+	if errMsg == "" {
+		recovered = prev == HealthQuarantined && cur.Missed
+		cur.State, cur.ConsecutiveFailures, cur.LastError, cur.Missed = HealthHealthy, 0, "", false
+	} else {
+		cur.ConsecutiveFailures++
+		cur.LastError = errMsg
+		cur.State = HealthUnhealthy
+		if cur.ConsecutiveFailures >= p.threshold {
+			cur.State = HealthQuarantined
+		}
+	}
+	if cur.State != prev {
+		cur.Since = at
+	}
+	return *cur, cur.State != prev, recovered
+}
+
+// 삭제된 client 정리
+func (p *Prober) prune(keep map[string]bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key := range p.status {
+		if !keep[key] {
+			delete(p.status, key)
+		}
+	}
+}
+
+// GET <url>/<health_path>, 2xx가 아니면 실패 사유
+func (p *Prober) probe(ctx context.Context, rec Record) string {
+	path := rec.HealthPath
+	if path == "" {
+		path = p.path
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hookURL(rec.URL, path), nil)
+	if err != nil {
+		return err.Error()
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err.Error()
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return ""
+}
+
+// 등록 정보에 health check 결과 추가
+func (b *Client) WithHealth(records []Record) []Record {
+	if b.health == nil {
+		return records
+	}
+	out := make([]Record, 0, len(records))
+	for _, r := range records {
+		if st, ok := b.health.Status(r.Service, r.ID); ok {
+			r.Health = &st
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// interval마다 모든 client health check
+// threshold 이상 연속 실패한 client는 quarantine (Hook 대상에서 제외), 회복 시 최신 bundle 알림 전송
+func (b *Client) RunHealthCheck(ctx context.Context, logger *zap.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.checkHealth(ctx, logger)
+		}
+	}
+}
+
+func (b *Client) checkHealth(ctx context.Context, logger *zap.Logger) {
+	all, err := b.registry.List(ctx)
+	if err != nil {
+		logger.Error("failed to read clients for health check", zap.Error(err))
+		return
+	}
+
+	keep := map[string]bool{}
+	sem := make(chan struct{}, b.concurrency)
+	var wg sync.WaitGroup
+
+	for _, records := range all {
+		for _, rec := range records {
+			keep[healthKey(rec.Service, rec.ID)] = true
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(rec Record) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				errMsg := b.health.probe(ctx, rec)
+				st, changed, recovered := b.health.update(rec.Service, rec.ID, time.Now(), errMsg)
+				if changed {
+					fields := []zap.Field{
+						zap.String("service", rec.Service),
+						zap.String("client", rec.ID),
+						zap.String("url", rec.URL),
+						zap.String("state", st.State),
+						zap.Int("failures", st.ConsecutiveFailures),
+					}
+					switch st.State {
+					case HealthHealthy:
+						logger.Info("client recovered", fields...)
+					case HealthQuarantined:
+						logger.Error("client quarantined", append(fields, zap.String("error", errMsg))...)
+					default:
+						logger.Warn("client health check failed", append(fields, zap.String("error", errMsg))...)
+					}
+				}
+				if recovered {
+					b.notifyLatest(ctx, logger, rec)
+				}
+			}(rec)
+		}
+	}
+	wg.Wait()
+
+	b.health.prune(keep)
+}
+
+// quarantine 중 놓친 알림 대신 최신 bundle 알림 전송
+// outbox에 남아있는 이전 알림은 enqueue 시 superseded로 삭제되어 함께 전송되지 않음
+func (b *Client) notifyLatest(ctx context.Context, logger *zap.Logger, rec Record) {
+	bd, ok := b.Bundle[rec.Service]
+	if !ok {
+		return
+	}
+	major, minor := bd.Latest.GetMajor(), bd.Latest.GetMinor()
+	if major == 0 && minor == 0 {
+		return
+	}
+
+	now := time.Now()
+	event := NewHookEvent(rec.Service, bundle.TypeRegular, major, minor, bd.GetEtag(), now)
+	d, err := b.Outbox.enqueue(rec, rec.hookURL(DefaultHookPath), event, now, true)
+	if err != nil {
+		logger.Error("failed to event notification", zap.String("service", rec.Service), zap.String("client", rec.ID), zap.Error(err))
+		return
+	}
+	b.Outbox.dispatch(ctx, logger, []Delivery{*d}, b.deliver, 1)
+}
@@ -0,0 +1,126 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"go.uber.org/zap"
+)
+
+func TestProberUpdate(t *testing.T) {
+	p := NewProber("", 2, time.Second, http.DefaultClient)
+	now := time.Unix(1700000000, 0)
+
+	st, changed, _ := p.update("casb", "a", now, "")
+	if st.State != HealthHealthy || changed {
+		t.Fatalf("unexpected status: %+v, changed=%v", st, changed)
+	}
+	st, changed, _ = p.update("casb", "a", now.Add(time.Second), "connection refused")
+	if st.State != HealthUnhealthy || !changed || p.skip("casb", "a") {
+		t.Fatalf("unhealthy client must not be skipped yet: %+v", st)
+	}
+	st, _, _ = p.update("casb", "a", now.Add(2*time.Second), "connection refused")
+	if st.State != HealthQuarantined || st.ConsecutiveFailures != 2 || !p.skip("casb", "a") {
+		t.Fatalf("expected quarantined client, got %+v", st)
+	}
+
+	st, changed, recovered := p.update("casb", "a", now.Add(3*time.Second), "")
+	if st.State != HealthHealthy || !changed || !recovered || st.Missed {
+		t.Fatalf("expected recovery with missed notification, got %+v, recovered=%v", st, recovered)
+	}
+
+	p.prune(map[string]bool{})
+	if _, ok := p.Status("casb", "a"); ok {
+		t.Fatalf("deleted client must be pruned")
+	}
+}
+
+func TestHealthCheckQuarantine(t *testing.T) {
+	var (
+		healthy atomic.Bool
+		hooks   = make(chan HookEvent, 10)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if !healthy.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/hooks/bundle-update":
+			var e HookEvent
+			json.NewDecoder(r.Body).Decode(&e)
+			hooks <- e
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	registry := NewMemoryRegistry()
+	if err := registry.Add(ctx, []Record{{ID: "opa-1", Service: "casb", URL: srv.URL}}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	o, err := NewOutbox(t.TempDir(), Backoff{Initial: time.Minute, Max: time.Minute, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	b := &Client{
+		registry:    registry,
+		Outbox:      o,
+		Bundle:      map[string]*bundle.Bundle{"casb": {Latest: &bundle.Version{Minor: 3}}},
+		health:      NewProber("", 1, time.Second, http.DefaultClient),
+		httpClient:  http.DefaultClient,
+		timeout:     time.Second,
+		concurrency: 4,
+	}
+	logger := zap.NewNop()
+
+	b.checkHealth(ctx, logger)
+	records, _ := b.Get(ctx, "casb")
+	if h := b.WithHealth(records)[0].Health; h == nil || h.State != HealthQuarantined {
+		t.Fatalf("expected quarantined client, got %+v", h)
+	}
+
+	// quarantine된 client는 Hook 대상에서 제외
+	results, err := b.Hook(ctx, logger, DefaultHookPath, NewHookEvent("casb", "regular", 0, 2, `"x"`, time.Now()))
+	if err != nil || len(results) != 0 || len(hooks) != 0 {
+		t.Fatalf("quarantined client must be skipped: %+v, %v", results, err)
+	}
+
+	// quarantine 전에 실패해 outbox에 남은 재시도도 worker가 보내지 않음
+	if _, err := o.Enqueue(records[0], srv.URL+"/hooks/bundle-update", NewHookEvent("casb", "regular", 0, 1, `"y"`, time.Now()), time.Now()); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if due := o.due(time.Now(), b.quarantined); len(due) != 0 {
+		t.Fatalf("deliveries to quarantined client must not be sent: %+v", due)
+	}
+
+	// 회복 시 최신 bundle 알림 (이전 재시도는 대체되어 전송되지 않음)
+	healthy.Store(true)
+	b.checkHealth(ctx, logger)
+	select {
+	case e := <-hooks:
+		if e.Version != "v0.3" || e.Service != "casb" {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("recovered client must receive the latest bundle notification")
+	}
+	if list := o.List("casb", ""); len(list) != 0 {
+		t.Fatalf("stale deliveries must be superseded on recovery: %+v", list)
+	}
+
+	// 놓친 알림이 없으면 다시 보내지 않음
+	b.checkHealth(ctx, logger)
+	if len(hooks) != 0 {
+		t.Fatalf("unexpected notification")
+	}
+}
@@ -0,0 +1,129 @@
+package clients
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/config"
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+)
+
+const (
+	SignatureHeader = "X-Signature" // t=<unix>,v1=<hex(HMAC-SHA256(secret, "<t>.<body>"))>
+	EventIDHeader   = "X-Event-ID"
+
+	// 수신측 기본 허용 시간 오차 (replay 방지)
+	DefaultSignatureTolerance = 5 * time.Minute
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleSignature   = errors.New("webhook signature timestamp out of tolerance")
+)
+
+// hooks/bundle-update 요청 body
+type HookEvent struct {
+	ID        string    `json:"id"` // 이벤트마다 고유, 수신측 중복 처리 방지용
+	Service   string    `json:"service"`
+	Type      string    `json:"type"` // "regular" | "delta"
+	Version   string    `json:"version"`
+	ETag      string    `json:"etag"` // GET /services/{service}/bundle 응답의 ETag (따옴표 포함)
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// 새로 게시된 bundle 버전에 대한 이벤트
+func NewHookEvent(service, typ string, major, minor int, etag string, now time.Time) HookEvent {
+	return HookEvent{
+		ID:        newEventID(),
+		Service:   service,
+		Type:      typ,
+		Version:   bundle.Revision(major, minor),
+		ETag:      etag,
+		Timestamp: now.UTC(),
+	}
+}
+
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// X-Signature 헤더 값
+func SignPayload(secret, body []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, signature(secret, ts, body))
+}
+
+func signature(secret []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// 수신측 검증: 서명 일치 및 timestamp가 now 기준 tolerance 이내
+// 동일 X-Event-ID의 재전송은 수신측에서 tolerance 동안 기억하여 거부
+func VerifySignature(secret, body []byte, header string, now time.Time, tolerance time.Duration) error {
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	if ts == "" || len(sigs) == 0 {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return ErrStaleSignature
+	}
+
+	expected := signature(secret, ts, body)
+	for _, sig := range sigs {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// config clients.secrets => client 주소별 secret
+func LoadSecrets(cfgs []config.ClientSecretConfig) (map[string][]byte, error) {
+	secrets := make(map[string][]byte, len(cfgs))
+	for _, cfg := range cfgs {
+		data, err := os.ReadFile(cfg.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook secret for %s: %w", cfg.Client, err)
+		}
+		secret := []byte(strings.TrimSpace(string(data)))
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("empty webhook secret for %s", cfg.Client)
+		}
+		secrets[strings.TrimRight(cfg.Client, "/")] = secret
+	}
+	return secrets, nil
+}
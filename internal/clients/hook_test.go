@@ -0,0 +1,31 @@
+package clients
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("s3cr3t")
+	body := []byte(`{"service":"casb","version":"v1.2"}`)
+	now := time.Unix(1700000000, 0)
+
+	header := SignPayload(secret, body, now)
+	if err := VerifySignature(secret, body, header, now.Add(time.Minute), DefaultSignatureTolerance); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	if err := VerifySignature([]byte("other"), body, header, now, DefaultSignatureTolerance); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+	if err := VerifySignature(secret, []byte(`{}`), header, now, DefaultSignatureTolerance); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for tampered body, got %v", err)
+	}
+	if err := VerifySignature(secret, body, header, now.Add(10*time.Minute), DefaultSignatureTolerance); !errors.Is(err, ErrStaleSignature) {
+		t.Fatalf("expected ErrStaleSignature for replayed request, got %v", err)
+	}
+	if err := VerifySignature(secret, body, "garbage", now, DefaultSignatureTolerance); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for malformed header, got %v", err)
+	}
+}
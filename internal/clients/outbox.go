@@ -0,0 +1,448 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	DeliveryPending = "pending" // 전송 대기 또는 재시도 대기
+	DeliveryFailed  = "failed"  // max_age 초과로 재시도 중단 (redeliver로 재개)
+
+	DeliveryDelivered  = "delivered"  // 전송 결과에만 사용 (outbox에서 삭제됨)
+	DeliverySuperseded = "superseded" // 전송 결과에만 사용 (같은 client의 새 event로 대체되어 삭제됨)
+)
+
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+func (a DeliveryAttempt) ok() bool {
+	return a.Error == "" && a.StatusCode > 0 && a.StatusCode < 400
+}
+
+// client 하나에 대한 webhook 전송 단위 (<opa_data_path>/outbox/<service>/<id>.json)
+// 전송에 성공하거나 같은 client의 새 event가 등록되면 outbox에서 삭제
+type Delivery struct {
+	ID            string            `json:"id"`
+	Service       string            `json:"service"`
+	ClientID      string            `json:"client_id"`
+	Target        string            `json:"target"` // client 주소
+	URL           string            `json:"url"`    // 요청 URL (target + hook path)
+	Method        string            `json:"method"`
+	Event         HookEvent         `json:"event"`
+	Status        string            `json:"status"`
+	Attempts      []DeliveryAttempt `json:"attempts"`
+	CreatedAt     time.Time         `json:"created_at"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	ExpiresAt     time.Time         `json:"expires_at"` // 이후 실패하면 failed
+}
+
+// 재시도 간격: Initial * 2^(n-1) (최대 Max), 절반은 jitter
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	MaxAge  time.Duration // 최초 등록(또는 redeliver) 후 재시도 기간
+}
+
+func (b Backoff) next(attempts int) time.Duration {
+	d := b.Initial
+	for i := 1; i < attempts && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// 전송 결과 (delivery 1건)
+type DeliveryResult struct {
+	DeliveryID string `json:"delivery_id"`
+	URL        string `json:"url"`
+	DeliveryAttempt
+	Status string `json:"status"` // 전송 후 상태 (delivered | pending | failed)
+}
+
+type Outbox struct {
+	dir        string
+	backoff    Backoff
+	deliveries map[string]*Delivery
+	sending    map[string]struct{} // 전송 중인 delivery (중복 전송 방지)
+	wake       chan struct{}
+	mu         sync.Mutex
+}
+
+// dir 하위에 남아있는 delivery를 불러옴 (재시작 전 전송하지 못한 알림)
+func NewOutbox(dir string, backoff Backoff) (*Outbox, error) {
+	o := &Outbox{
+		dir:        dir,
+		backoff:    backoff,
+		deliveries: make(map[string]*Delivery),
+		sending:    make(map[string]struct{}),
+		wake:       make(chan struct{}, 1),
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read delivery: %w", err)
+		}
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("failed to decode delivery %s: %w", f, err)
+		}
+		o.deliveries[d.ID] = &d
+	}
+
+	// 재시작 전 삭제하지 못한 이전 event
+	for _, d := range o.deliveries {
+		if o.superseded(d) {
+			if err := o.remove(d); err != nil {
+				return nil, fmt.Errorf("failed to remove superseded delivery: %w", err)
+			}
+		}
+	}
+	return o, nil
+}
+
+// 같은 service, client에 대한 delivery인지 여부
+func (d *Delivery) sameClient(other *Delivery) bool {
+	if d.Service != other.Service {
+		return false
+	}
+	if d.ClientID != "" || other.ClientID != "" {
+		return d.ClientID == other.ClientID
+	}
+	return d.Target == other.Target
+}
+
+// 같은 client에 더 나중에 등록된 delivery가 있는지 여부 (caller가 mu를 잡고 호출)
+// 이전 event를 재시도하면 새 버전 이후에 도착해 client가 이전 버전으로 되돌아갈 수 있음
+func (o *Outbox) superseded(d *Delivery) bool {
+	for _, other := range o.deliveries {
+		if other.ID != d.ID && other.sameClient(d) && other.CreatedAt.After(d.CreatedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// caller가 mu를 잡고 호출
+func (o *Outbox) remove(d *Delivery) error {
+	delete(o.deliveries, d.ID)
+	if err := os.Remove(o.path(d)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (o *Outbox) path(d *Delivery) string {
+	return filepath.Join(o.dir, d.Service, d.ID+".json")
+}
+
+// caller가 mu를 잡고 호출
+func (o *Outbox) save(d *Delivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return utils.SaveToFile(context.Background(), bytes.NewReader(data), o.path(d))
+}
+
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// worker가 전송하도록 등록
+func (o *Outbox) Enqueue(client Record, url string, event HookEvent, now time.Time) (*Delivery, error) {
+	d, err := o.enqueue(client, url, event, now, false)
+	if err != nil {
+		return nil, err
+	}
+	o.notify()
+	return d, nil
+}
+
+// claim이면 caller가 직접 전송 (worker는 complete 전까지 전송하지 않음)
+// 같은 client의 이전 delivery(pending, failed)는 삭제, 전송 중인 delivery는 complete에서 삭제
+func (o *Outbox) enqueue(client Record, url string, event HookEvent, now time.Time, claim bool) (*Delivery, error) {
+	d := &Delivery{
+		ID:            newEventID(),
+		Service:       client.Service,
+		ClientID:      client.ID,
+		Target:        client.URL,
+		URL:           url,
+		Method:        client.method(),
+		Event:         event,
+		Status:        DeliveryPending,
+		Attempts:      []DeliveryAttempt{},
+		CreatedAt:     now.UTC(),
+		NextAttemptAt: now.UTC(),
+		ExpiresAt:     now.Add(o.backoff.MaxAge).UTC(),
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.save(d); err != nil {
+		return nil, fmt.Errorf("failed to save delivery: %w", err)
+	}
+	o.deliveries[d.ID] = d
+	if claim {
+		o.sending[d.ID] = struct{}{}
+	}
+
+	for _, old := range o.deliveries {
+		if _, ok := o.sending[old.ID]; ok || old.ID == d.ID || !old.sameClient(d) || old.CreatedAt.After(d.CreatedAt) {
+			continue
+		}
+		if err := o.remove(old); err != nil {
+			return nil, fmt.Errorf("failed to remove superseded delivery: %w", err)
+		}
+	}
+
+	copied := *d
+	return &copied, nil
+}
+
+// service의 delivery (status가 비어있으면 전체), 최근 등록 순
+func (o *Outbox) List(service, status string) []Delivery {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	list := []Delivery{}
+	for _, d := range o.deliveries {
+		if d.Service != service || (status != "" && d.Status != status) {
+			continue
+		}
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// 즉시 다시 전송 (failed인 경우 재시도 기간도 새로 시작)
+func (o *Outbox) Redeliver(service, id string, now time.Time) (*Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	d, ok := o.deliveries[id]
+	if !ok || d.Service != service {
+		return nil, fmt.Errorf("%w: %s", ErrDeliveryNotFound, id)
+	}
+
+	d.Status = DeliveryPending
+	d.NextAttemptAt = now.UTC()
+	d.ExpiresAt = now.Add(o.backoff.MaxAge).UTC()
+	if err := o.save(d); err != nil {
+		return nil, fmt.Errorf("failed to save delivery: %w", err)
+	}
+	o.notify()
+
+	copied := *d
+	return &copied, nil
+}
+
+// 전송 시각이 된 pending delivery (전송 중으로 표시, complete에서 해제)
+// 같은 client에 새 delivery가 있으면 이전 delivery는 전송하지 않음
+// skip(e.g. quarantine된 client)이면 backoff.Max 후로 미룸
+func (o *Outbox) due(now time.Time, skip func(Delivery) bool) []Delivery {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var list []Delivery
+	for _, d := range o.deliveries {
+		if _, ok := o.sending[d.ID]; ok {
+			continue
+		}
+		if d.Status == DeliveryPending && !d.NextAttemptAt.After(now) && !o.superseded(d) {
+			if skip != nil && skip(*d) {
+				d.NextAttemptAt = now.Add(o.backoff.Max).UTC()
+				continue
+			}
+			o.sending[d.ID] = struct{}{}
+			list = append(list, *d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// 전송 결과 기록: 성공 시 삭제, 실패 시 backoff 후 재시도 또는 failed
+// 전송 중 같은 client의 새 delivery가 등록된 경우 실패해도 재시도하지 않고 삭제 (superseded)
+func (o *Outbox) complete(id string, attempt DeliveryAttempt, now time.Time) (*Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.sending, id)
+	d, ok := o.deliveries[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDeliveryNotFound, id) // 전송 중 삭제됨
+	}
+	d.Attempts = append(d.Attempts, attempt)
+
+	if attempt.ok() {
+		return d, o.remove(d)
+	}
+	if o.superseded(d) {
+		d.Status = DeliverySuperseded
+		return d, o.remove(d)
+	}
+
+	if !now.Before(d.ExpiresAt) {
+		d.Status = DeliveryFailed
+	} else {
+		d.NextAttemptAt = now.Add(o.backoff.next(len(d.Attempts))).UTC()
+	}
+	return d, o.save(d)
+}
+
+// 다음 재시도 시각까지 대기 시간 (pending이 없으면 interval)
+func (o *Outbox) wait(now time.Time, interval time.Duration) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	wait := interval
+	for _, d := range o.deliveries {
+		if _, ok := o.sending[d.ID]; ok || d.Status != DeliveryPending {
+			continue
+		}
+		if w := d.NextAttemptAt.Sub(now); w < wait {
+			wait = w
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// pending delivery를 send로 전송 (Enqueue, Redeliver 시 즉시 깨어남), 동시에 최대 limit건
+// skip이 true인 delivery는 전송하지 않음 (nil이면 모두 전송)
+func (o *Outbox) Run(ctx context.Context, logger *zap.Logger, send func(context.Context, Delivery) DeliveryAttempt, limit int, skip func(Delivery) bool) {
+	const interval = time.Minute
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		case <-timer.C:
+		}
+
+		o.dispatch(ctx, logger, o.due(time.Now(), skip), send, limit)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(o.wait(time.Now(), interval))
+	}
+}
+
+// deliveries를 최대 limit건씩 동시에 전송하고 결과를 기록 (deliveries 순서대로 반환)
+// 느린 client가 있어도 전체 소요 시간은 ceil(n/limit) * timeout 이내
+func (o *Outbox) dispatch(ctx context.Context, logger *zap.Logger, deliveries []Delivery, send func(context.Context, Delivery) DeliveryAttempt, limit int) []DeliveryResult {
+	if limit <= 0 {
+		limit = 1
+	}
+	results := make([]DeliveryResult, len(deliveries))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, d := range deliveries {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, d Delivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			attempt := send(ctx, d)
+			results[i] = DeliveryResult{DeliveryID: d.ID, URL: d.URL, DeliveryAttempt: attempt, Status: DeliveryDelivered}
+
+			done, err := o.complete(d.ID, attempt, time.Now())
+			if err != nil {
+				logger.Error("failed to record delivery attempt", zap.String("delivery", d.ID), zap.Error(err))
+				return
+			}
+			if !attempt.ok() {
+				results[i].Status = done.Status
+			}
+			logDelivery(logger, done, attempt)
+		}(i, d)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func logDelivery(logger *zap.Logger, d *Delivery, attempt DeliveryAttempt) {
+	fields := []zap.Field{
+		zap.String("service", d.Service),
+		zap.String("target", d.URL),
+		zap.String("delivery", d.ID),
+		zap.String("version", d.Event.Version),
+		zap.Int("attempt", len(d.Attempts)),
+	}
+	switch {
+	case attempt.ok():
+		logger.Info("event notification delivered", fields...)
+	case d.Status == DeliverySuperseded:
+		logger.Warn("failed to event notification, superseded by a newer event", append(fields, zap.String("error", attemptError(attempt)))...)
+	case d.Status == DeliveryFailed:
+		logger.Error("failed to event notification, giving up", append(fields, zap.String("error", attemptError(attempt)))...)
+	default:
+		logger.Warn("failed to event notification, will retry", append(fields,
+			zap.String("error", attemptError(attempt)),
+			zap.Time("next_attempt_at", d.NextAttemptAt),
+		)...)
+	}
+}
+
+func attemptError(a DeliveryAttempt) string {
+	if a.Error != "" {
+		return a.Error
+	}
+	return fmt.Sprintf("unexpected status %d", a.StatusCode)
+}
+
+// hook path를 포함한 요청 URL (e.g. "http://127.0.0.1:5556" + "hooks/bundle-update")
+func hookURL(addr, path string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimRight(addr, "/"), strings.TrimLeft(path, "/"))
+}
@@ -0,0 +1,186 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestOutbox(t *testing.T) {
+	dir := t.TempDir()
+	backoff := Backoff{Initial: time.Second, Max: 4 * time.Second, MaxAge: time.Minute}
+	now := time.Unix(1700000000, 0)
+
+	o, err := NewOutbox(dir, backoff)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	event := NewHookEvent("casb", "regular", 0, 2, `"abc"`, now)
+	client := Record{ID: "opa-1", Service: "casb", URL: "http://127.0.0.1:5556"}
+	d, err := o.Enqueue(client, "http://127.0.0.1:5556/hooks/bundle-update", event, now)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if due := o.due(now, nil); len(due) != 1 || due[0].ID != d.ID {
+		t.Fatalf("expected delivery to be due, got %v", due)
+	}
+
+	// 실패: backoff 후 재시도
+	failed, err := o.complete(d.ID, DeliveryAttempt{At: now, Error: "connection refused"}, now)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if wait := failed.NextAttemptAt.Sub(now); failed.Status != DeliveryPending || wait < 500*time.Millisecond || wait > time.Second {
+		t.Fatalf("unexpected retry schedule: %s in %s", failed.Status, wait)
+	}
+	if len(o.due(now, nil)) != 0 {
+		t.Fatalf("delivery must not be due before backoff")
+	}
+
+	// 재시작 후에도 유지
+	o, err = NewOutbox(dir, backoff)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	list := o.List("casb", DeliveryPending)
+	if len(list) != 1 || len(list[0].Attempts) != 1 || list[0].Event.Version != "v0.2" || list[0].ClientID != "opa-1" || list[0].Method != "POST" {
+		t.Fatalf("unexpected deliveries after reload: %+v", list)
+	}
+
+	// max_age 초과: failed
+	later := now.Add(2 * time.Minute)
+	failed, err = o.complete(d.ID, DeliveryAttempt{At: later, StatusCode: 503}, later)
+	if err != nil || failed.Status != DeliveryFailed {
+		t.Fatalf("expected failed delivery, got %+v, %v", failed, err)
+	}
+	if len(o.List("casb", DeliveryFailed)) != 1 || len(o.due(later.Add(time.Hour), nil)) != 0 {
+		t.Fatalf("failed delivery must not be retried")
+	}
+
+	if _, err := o.Redeliver("ztna", d.ID, later); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("expected ErrDeliveryNotFound, got %v", err)
+	}
+	if _, err := o.Redeliver("casb", d.ID, later); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(o.due(later, nil)) != 1 {
+		t.Fatalf("redelivered delivery must be due")
+	}
+
+	// 성공: outbox에서 삭제
+	if _, err := o.complete(d.ID, DeliveryAttempt{At: later, StatusCode: 200}, later); err != nil {
+		t.Fatalf("%v", err)
+	}
+	o, err = NewOutbox(dir, backoff)
+	if err != nil || len(o.List("casb", "")) != 0 {
+		t.Fatalf("expected empty outbox, got %v, %v", o.List("casb", ""), err)
+	}
+}
+
+func TestOutboxRun(t *testing.T) {
+	o, err := NewOutbox(t.TempDir(), Backoff{Initial: 10 * time.Millisecond, Max: 10 * time.Millisecond, MaxAge: time.Minute})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	sent := make(chan int, 10)
+	calls := 0
+	send := func(ctx context.Context, d Delivery) DeliveryAttempt {
+		calls++
+		sent <- calls
+		if calls < 3 {
+			return DeliveryAttempt{At: time.Now(), StatusCode: 500}
+		}
+		return DeliveryAttempt{At: time.Now(), StatusCode: 204}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go o.Run(ctx, zap.NewNop(), send, 4, nil)
+
+	now := time.Now()
+	client := Record{ID: "a", Service: "casb", URL: "http://a"}
+	if _, err := o.Enqueue(client, "http://a/hooks/bundle-update", NewHookEvent("casb", "regular", 0, 1, `"x"`, now), now); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for want := 1; want <= 3; want++ {
+		select {
+		case n := <-sent:
+			if n != want {
+				t.Fatalf("unexpected attempt %d", n)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("attempt %d was not sent", want)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(o.List("casb", "")) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("delivered webhook must be removed from outbox")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutboxSupersede(t *testing.T) {
+	dir := t.TempDir()
+	backoff := Backoff{Initial: time.Second, Max: time.Second, MaxAge: time.Minute}
+	now := time.Unix(1700000000, 0)
+
+	o, err := NewOutbox(dir, backoff)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	a := Record{ID: "opa-1", Service: "casb", URL: "http://a"}
+	b := Record{ID: "opa-2", Service: "casb", URL: "http://b"}
+
+	// client가 다운된 동안 v0.1, v0.2 게시: v0.1은 재시도하지 않음
+	if _, err := o.Enqueue(a, "http://a/hooks/bundle-update", NewHookEvent("casb", "regular", 0, 1, `"1"`, now), now); err != nil {
+		t.Fatalf("%v", err)
+	}
+	other, err := o.Enqueue(b, "http://b/hooks/bundle-update", NewHookEvent("casb", "regular", 0, 1, `"1"`, now), now)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	later := now.Add(time.Second)
+	latest, err := o.Enqueue(a, "http://a/hooks/bundle-update", NewHookEvent("casb", "regular", 0, 2, `"2"`, later), later)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if list := o.List("casb", ""); len(list) != 2 || list[0].ID != latest.ID || list[1].ID != other.ID {
+		t.Fatalf("expected only the newest delivery per client, got %+v", list)
+	}
+
+	// 전송 중인 이전 delivery는 실패해도 재시도하지 않고 삭제
+	inflight := o.due(later, nil)
+	if len(inflight) != 2 {
+		t.Fatalf("expected 2 due deliveries, got %+v", inflight)
+	}
+	newest, err := o.Enqueue(a, "http://a/hooks/bundle-update", NewHookEvent("casb", "delta", 0, 3, `"3"`, later.Add(time.Second)), later.Add(time.Second))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if list := o.List("casb", ""); len(list) != 3 {
+		t.Fatalf("in-flight delivery must be kept until completed, got %+v", list)
+	}
+	done, err := o.complete(latest.ID, DeliveryAttempt{At: later, Error: "connection refused"}, later)
+	if err != nil || done.Status != DeliverySuperseded {
+		t.Fatalf("expected superseded delivery, got %+v, %v", done, err)
+	}
+	if due := o.due(later.Add(time.Second), nil); len(due) != 1 || due[0].ID != newest.ID {
+		t.Fatalf("expected only the newest delivery to be due, got %+v", due)
+	}
+
+	o, err = NewOutbox(dir, backoff)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if list := o.List("casb", ""); len(list) != 2 {
+		t.Fatalf("unexpected deliveries after reload: %+v", list)
+	}
+}
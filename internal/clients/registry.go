@@ -0,0 +1,344 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/config"
+	"github.com/jjhwan-h/bundle-server/database"
+	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+)
+
+const (
+	RegistryFile   = "file"
+	RegistryMySQL  = "mysql"
+	RegistryMemory = "memory"
+
+	DefaultHookPath = "hooks/bundle-update"
+)
+
+var (
+	ErrInvalidClient = errors.New("invalid client")
+
+	clientIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+)
+
+// webhook client 등록 정보
+type Record struct {
+	ID         string            `json:"id"` // service 내 고유 (생략 시 자동 생성)
+	Service    string            `json:"service"`
+	URL        string            `json:"url"` // client 주소 (e.g. "http://127.0.0.1:5556")
+	Labels     map[string]string `json:"labels,omitempty"`
+	HookPath   string            `json:"hook_path,omitempty"`   // 기본값 hooks/bundle-update
+	HookMethod string            `json:"hook_method,omitempty"` // 기본값 POST
+	HealthPath string            `json:"health_path,omitempty"` // 기본값 clients.health.path
+	Secret     string            `json:"secret,omitempty"`      // webhook 서명 secret (조회 시 제외)
+	HasSecret  bool              `json:"has_secret"`
+	CreatedAt  time.Time         `json:"created_at"`
+
+	LastSuccess *time.Time `json:"last_success,omitempty"` // 마지막 webhook 전송 성공
+	LastFailure *time.Time `json:"last_failure,omitempty"` // 마지막 webhook 전송 실패
+	LastError   string     `json:"last_error,omitempty"`
+
+	Health *HealthStatus `json:"health,omitempty"` // 조회 시에만 포함 (저장하지 않음)
+}
+
+// 응답용 (secret 제외)
+func (r Record) Redacted() Record {
+	r.HasSecret = r.Secret != ""
+	r.Secret = ""
+	return r
+}
+
+// 등록 요청 검사 및 기본값 설정
+func (r *Record) normalize(service string, now time.Time) error {
+	r.Service = service
+	r.URL = trimURL(r.URL)
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL: %q", ErrInvalidClient, r.URL)
+	}
+
+	if r.ID == "" {
+		r.ID = newEventID()[:12]
+	}
+	if !clientIDPattern.MatchString(r.ID) {
+		return fmt.Errorf("%w: invalid id %q", ErrInvalidClient, r.ID)
+	}
+
+	r.HookPath = strings.TrimLeft(r.HookPath, "/")
+	r.HealthPath = strings.TrimLeft(r.HealthPath, "/")
+	r.HookMethod = strings.ToUpper(r.HookMethod)
+	switch r.HookMethod {
+	case "":
+	case http.MethodPost, http.MethodPut:
+	default:
+		return fmt.Errorf("%w: hook_method must be POST or PUT: %q", ErrInvalidClient, r.HookMethod)
+	}
+	for k := range r.Labels {
+		if !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("%w: invalid label key %q", ErrInvalidClient, k)
+		}
+	}
+
+	r.HasSecret = r.Secret != ""
+	r.CreatedAt = now.UTC()
+	r.LastSuccess, r.LastFailure, r.LastError, r.Health = nil, nil, "", nil
+	return nil
+}
+
+// webhook 요청 URL: hook_path가 설정되면 path의 경로 대신 사용 (query는 유지)
+func (r Record) hookURL(path string) string {
+	if r.HookPath != "" {
+		p, query, _ := strings.Cut(path, "?")
+		p = r.HookPath
+		if query != "" {
+			p += "?" + query
+		}
+		path = p
+	}
+	return hookURL(r.URL, path)
+}
+
+func (r Record) method() string {
+	if r.HookMethod == "" {
+		return http.MethodPost
+	}
+	return r.HookMethod
+}
+
+// 전송 결과 반영 (errMsg가 비어있으면 성공)
+func (r *Record) setResult(at time.Time, errMsg string) {
+	at = at.UTC()
+	if errMsg == "" {
+		r.LastSuccess = &at
+		return
+	}
+	r.LastFailure = &at
+	r.LastError = errMsg
+}
+
+// 주소 목록
+func URLs(records []Record) []string {
+	urls := make([]string, 0, len(records))
+	for _, r := range records {
+		urls = append(urls, r.URL)
+	}
+	return urls
+}
+
+// 등록된 client 저장소 (같은 저장소를 사용하는 bundle-server 인스턴스 간 공유)
+type Registry interface {
+	List(ctx context.Context) (map[string][]Record, error)
+	Get(ctx context.Context, service string) ([]Record, error)
+	// id 또는 url이 하나라도 이미 등록되어 있으면 appErr.ErrAlreadyRegistered (모두 등록하지 않음)
+	Add(ctx context.Context, records []Record) error
+	// key는 id 또는 url, 등록되어 있지 않으면 appErr.ErrClientNotFound
+	Delete(ctx context.Context, service, key string) error
+	DeleteAll(ctx context.Context, service string) error
+	// webhook 전송 결과 (errMsg가 비어있으면 성공)
+	RecordResult(ctx context.Context, service, id string, at time.Time, errMsg string) error
+	// service별로 한 번만 records 등록 (이미 seed된 service는 무시)
+	// 등록된 client가 있으면 seed 여부만 기록
+	Seed(ctx context.Context, service string, records []Record) error
+}
+
+// config clients.registry에 따른 저장소
+func NewRegistry(cfg config.RegistryConfig) (Registry, error) {
+	switch cfg.Type {
+	case "", RegistryFile:
+		path := cfg.File
+		if path == "" {
+			path = filepath.Join(config.Cfg.OpaDataPath, "clients.json")
+		}
+		return NewFileRegistry(path), nil
+	case RegistryMySQL:
+		db := database.GetDB(cfg.Database)
+		if db == nil {
+			return nil, fmt.Errorf("database %q is not initialized (check db.database)", cfg.Database)
+		}
+		return NewMySQLRegistry(context.Background(), db)
+	case RegistryMemory:
+		return NewMemoryRegistry(), nil
+	default:
+		return nil, fmt.Errorf("unsupported client registry type %q", cfg.Type)
+	}
+}
+
+// config.yaml의 client 목록 등록 (service별 최초 1회)
+// 이후 API로 삭제한 client는 재시작해도 다시 등록되지 않음
+func seedRegistry(ctx context.Context, r Registry, clients map[string][]string, now time.Time) error {
+	for service, addrs := range clients {
+		var records []Record
+		seen := map[string]bool{}
+		for _, addr := range addrs {
+			rec := Record{URL: addr}
+			if addr == "" || rec.normalize(service, now) != nil || seen[rec.URL] {
+				continue
+			}
+			seen[rec.URL] = true
+			records = append(records, rec)
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if err := r.Seed(ctx, service, records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 중복 검사: 이미 등록된 client(existing) 또는 records 내 id, url 중복
+func checkDuplicates(existing map[string][]Record, records []Record) error {
+	seen := map[string]bool{}
+	for service, rs := range existing {
+		for _, r := range rs {
+			seen[service+"\x00id\x00"+r.ID] = true
+			seen[service+"\x00url\x00"+r.URL] = true
+		}
+	}
+	for _, r := range records {
+		idKey, urlKey := r.Service+"\x00id\x00"+r.ID, r.Service+"\x00url\x00"+r.URL
+		if seen[idKey] {
+			return fmt.Errorf("%w : id %s", appErr.ErrAlreadyRegistered, r.ID)
+		}
+		if seen[urlKey] {
+			return fmt.Errorf("%w : %s", appErr.ErrAlreadyRegistered, r.URL)
+		}
+		seen[idKey], seen[urlKey] = true, true
+	}
+	return nil
+}
+
+func (r Record) matches(key string) bool {
+	return r.ID == key || r.URL == trimURL(key)
+}
+
+func trimURL(u string) string {
+	return strings.TrimRight(strings.TrimSpace(u), "/")
+}
+
+type memoryRegistry struct {
+	data   map[string][]Record
+	seeded map[string]bool
+	mu     sync.Mutex
+}
+
+// 재시작 시 유지되지 않음 (테스트, 단일 인스턴스용)
+func NewMemoryRegistry() Registry {
+	return &memoryRegistry{data: make(map[string][]Record), seeded: make(map[string]bool)}
+}
+
+func (m *memoryRegistry) List(ctx context.Context) (map[string][]Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	all := make(map[string][]Record, len(m.data))
+	for service, records := range m.data {
+		all[service] = append([]Record(nil), records...)
+	}
+	return all, nil
+}
+
+func (m *memoryRegistry) Get(ctx context.Context, service string) ([]Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Record(nil), m.data[service]...), nil
+}
+
+func (m *memoryRegistry) Add(ctx context.Context, records []Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkDuplicates(m.data, records); err != nil {
+		return err
+	}
+	for _, r := range records {
+		m.data[r.Service] = append(m.data[r.Service], r)
+	}
+	return nil
+}
+
+func (m *memoryRegistry) Delete(ctx context.Context, service, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records, err := removeRecord(m.data[service], service, key)
+	if err != nil {
+		return err
+	}
+	m.data[service] = records
+	return nil
+}
+
+func (m *memoryRegistry) DeleteAll(ctx context.Context, service string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.data, service)
+	return nil
+}
+
+func (m *memoryRegistry) RecordResult(ctx context.Context, service, id string, at time.Time, errMsg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return setRecordResult(m.data[service], service, id, at, errMsg)
+}
+
+func (m *memoryRegistry) Seed(ctx context.Context, service string, records []Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.seeded[service] {
+		return nil
+	}
+	if len(m.data[service]) == 0 {
+		if err := checkDuplicates(m.data, records); err != nil {
+			return err
+		}
+		m.data[service] = append(m.data[service], records...)
+	}
+	m.seeded[service] = true
+	return nil
+}
+
+func removeRecord(records []Record, service, key string) ([]Record, error) {
+	var (
+		tmp   []Record
+		found bool
+	)
+	for _, r := range records {
+		if r.matches(key) {
+			found = true
+			continue
+		}
+		tmp = append(tmp, r)
+	}
+	if !found {
+		return nil, notFound(service, key)
+	}
+	return tmp, nil
+}
+
+func setRecordResult(records []Record, service, id string, at time.Time, errMsg string) error {
+	for i := range records {
+		if records[i].ID == id {
+			records[i].setResult(at, errMsg)
+			return nil
+		}
+	}
+	return notFound(service, id)
+}
+
+func notFound(service, key string) error {
+	return fmt.Errorf("%w: '%s' for service '%s'", appErr.ErrClientNotFound, key, service)
+}
@@ -0,0 +1,191 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/jjhwan-h/bundle-server/internal/utils"
+)
+
+// JSON 파일 저장소 ({"<service>": [Record, ...]})
+// 매 요청마다 파일을 읽으므로 같은 파일(e.g. 공유 볼륨)을 사용하는 인스턴스 간 공유
+// config.yaml의 client를 등록한 service 목록은 <path>.seeded에 저장
+type fileRegistry struct {
+	path string
+}
+
+func NewFileRegistry(path string) Registry {
+	return &fileRegistry{path: path}
+}
+
+func (f *fileRegistry) seededPath() string {
+	return f.path + ".seeded"
+}
+
+// lock을 잡은 상태에서 fn 실행 (exclusive: 쓰기)
+func (f *fileRegistry) withLock(ctx context.Context, exclusive bool, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+
+	lock := flock.New(f.path + ".lock") // 멀티 프로세스/컨테이너 환경에서의 쓰기 충돌 방지
+	var (
+		locked bool
+		err    error
+	)
+	if exclusive {
+		locked, err = lock.TryLockContext(ctx, 50*time.Millisecond)
+	} else {
+		locked, err = lock.TryRLockContext(ctx, 50*time.Millisecond)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !locked {
+		return fmt.Errorf("timeout: could not acquire file lock")
+	}
+	defer lock.Unlock()
+
+	return fn()
+}
+
+func (f *fileRegistry) read() (map[string][]Record, error) {
+	data := make(map[string][]Record)
+
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return data, nil
+		}
+		return nil, fmt.Errorf("failed to read client registry: %w", err)
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return data, nil
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode client registry: %w", err)
+	}
+	return data, nil
+}
+
+func (f *fileRegistry) write(data map[string][]Record) error {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.SaveToFile(context.Background(), bytes.NewReader(b), f.path)
+}
+
+// 읽기-수정-쓰기
+func (f *fileRegistry) update(ctx context.Context, fn func(map[string][]Record) error) error {
+	return f.withLock(ctx, true, func() error {
+		data, err := f.read()
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+		return f.write(data)
+	})
+}
+
+func (f *fileRegistry) List(ctx context.Context) (map[string][]Record, error) {
+	var data map[string][]Record
+	err := f.withLock(ctx, false, func() error {
+		var err error
+		data, err = f.read()
+		return err
+	})
+	return data, err
+}
+
+func (f *fileRegistry) Get(ctx context.Context, service string) ([]Record, error) {
+	data, err := f.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return data[service], nil
+}
+
+func (f *fileRegistry) Add(ctx context.Context, records []Record) error {
+	return f.update(ctx, func(data map[string][]Record) error {
+		if err := checkDuplicates(data, records); err != nil {
+			return err
+		}
+		for _, r := range records {
+			data[r.Service] = append(data[r.Service], r)
+		}
+		return nil
+	})
+}
+
+func (f *fileRegistry) Delete(ctx context.Context, service, key string) error {
+	return f.update(ctx, func(data map[string][]Record) error {
+		records, err := removeRecord(data[service], service, key)
+		if err != nil {
+			return err
+		}
+		data[service] = records
+		return nil
+	})
+}
+
+func (f *fileRegistry) DeleteAll(ctx context.Context, service string) error {
+	return f.update(ctx, func(data map[string][]Record) error {
+		delete(data, service)
+		return nil
+	})
+}
+
+func (f *fileRegistry) RecordResult(ctx context.Context, service, id string, at time.Time, errMsg string) error {
+	return f.update(ctx, func(data map[string][]Record) error {
+		return setRecordResult(data[service], service, id, at, errMsg)
+	})
+}
+
+func (f *fileRegistry) Seed(ctx context.Context, service string, records []Record) error {
+	return f.withLock(ctx, true, func() error {
+		var seeded []string
+		b, err := os.ReadFile(f.seededPath())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read seeded services: %w", err)
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			if err := json.Unmarshal(b, &seeded); err != nil {
+				return fmt.Errorf("failed to decode seeded services: %w", err)
+			}
+		}
+		if slices.Contains(seeded, service) {
+			return nil
+		}
+
+		data, err := f.read()
+		if err != nil {
+			return err
+		}
+		if len(data[service]) == 0 {
+			if err := checkDuplicates(data, records); err != nil {
+				return err
+			}
+			data[service] = append(data[service], records...)
+			if err := f.write(data); err != nil {
+				return err
+			}
+		}
+
+		// client 등록 후 기록 (기록 실패 시 다음 시작에서 등록된 client가 있으므로 다시 등록되지 않음)
+		if b, err = json.Marshal(append(seeded, service)); err != nil {
+			return err
+		}
+		return utils.SaveToFile(context.Background(), bytes.NewReader(b), f.seededPath())
+	})
+}
@@ -0,0 +1,208 @@
+package clients
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+
+	"github.com/uptrace/bun"
+)
+
+type TClient struct {
+	bun.BaseModel `bun:"table:bundle_server_client"`
+
+	Service     string            `bun:"service,pk,unique:service_url,type:varchar(64)" json:"service"`
+	ID          string            `bun:"id,pk,type:varchar(64)"            json:"id"`
+	URL         string            `bun:"url,notnull,unique:service_url,type:varchar(255)" json:"url"`
+	Labels      map[string]string `bun:"labels,type:json"                  json:"labels,omitempty"`
+	HookPath    string            `bun:"hook_path,type:varchar(255)"       json:"hook_path,omitempty"`
+	HookMethod  string            `bun:"hook_method,type:varchar(8)"       json:"hook_method,omitempty"`
+	HealthPath  string            `bun:"health_path,type:varchar(255)"     json:"health_path,omitempty"`
+	Secret      string            `bun:"secret,type:varchar(255)"          json:"-"`
+	CreatedAt   time.Time         `bun:"created_at,notnull"                json:"created_at"`
+	LastSuccess *time.Time        `bun:"last_success,nullzero"             json:"last_success,omitempty"`
+	LastFailure *time.Time        `bun:"last_failure,nullzero"             json:"last_failure,omitempty"`
+	LastError   string            `bun:"last_error,type:varchar(1024)"     json:"last_error,omitempty"`
+}
+
+// config.yaml의 client를 등록한 service
+type TClientSeed struct {
+	bun.BaseModel `bun:"table:bundle_server_client_seed"`
+
+	Service  string    `bun:"service,pk,type:varchar(64)"`
+	SeededAt time.Time `bun:"seeded_at,notnull"`
+}
+
+func (t *TClient) record() Record {
+	return Record{
+		ID:          t.ID,
+		Service:     t.Service,
+		URL:         t.URL,
+		Labels:      t.Labels,
+		HookPath:    t.HookPath,
+		HookMethod:  t.HookMethod,
+		HealthPath:  t.HealthPath,
+		Secret:      t.Secret,
+		HasSecret:   t.Secret != "",
+		CreatedAt:   t.CreatedAt.UTC(),
+		LastSuccess: t.LastSuccess,
+		LastFailure: t.LastFailure,
+		LastError:   t.LastError,
+	}
+}
+
+func newTClient(r Record) *TClient {
+	return &TClient{
+		Service:     r.Service,
+		ID:          r.ID,
+		URL:         r.URL,
+		Labels:      r.Labels,
+		HookPath:    r.HookPath,
+		HookMethod:  r.HookMethod,
+		HealthPath:  r.HealthPath,
+		Secret:      r.Secret,
+		CreatedAt:   r.CreatedAt,
+		LastSuccess: r.LastSuccess,
+		LastFailure: r.LastFailure,
+		LastError:   r.LastError,
+	}
+}
+
+// MySQL 저장소 (bundle_server_client, bundle_server_client_seed 테이블, 없으면 생성)
+type mysqlRegistry struct {
+	db *bun.DB
+}
+
+func NewMySQLRegistry(ctx context.Context, db *bun.DB) (Registry, error) {
+	for _, model := range []any{(*TClient)(nil), (*TClientSeed)(nil)} {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return nil, appErr.NewDBError(appErr.DB_QUERY_FAIL, "failed to create client table", err)
+		}
+	}
+	return &mysqlRegistry{db: db}, nil
+}
+
+func (m *mysqlRegistry) List(ctx context.Context) (map[string][]Record, error) {
+	var rows []TClient
+	if err := m.db.NewSelect().Model(&rows).Order("created_at").Scan(ctx); err != nil {
+		return nil, appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+	}
+
+	all := make(map[string][]Record)
+	for i := range rows {
+		all[rows[i].Service] = append(all[rows[i].Service], rows[i].record())
+	}
+	return all, nil
+}
+
+func (m *mysqlRegistry) Get(ctx context.Context, service string) ([]Record, error) {
+	var rows []TClient
+	err := m.db.NewSelect().Model(&rows).Where("service = ?", service).Order("created_at").Scan(ctx)
+	if err != nil {
+		return nil, appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+	}
+
+	records := make([]Record, 0, len(rows))
+	for i := range rows {
+		records = append(records, rows[i].record())
+	}
+	return records, nil
+}
+
+func (m *mysqlRegistry) Add(ctx context.Context, records []Record) error {
+	return m.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return addTx(ctx, tx, records)
+	})
+}
+
+func addTx(ctx context.Context, tx bun.Tx, records []Record) error {
+	existing := make(map[string][]Record)
+	for _, r := range records {
+		var rows []TClient
+		err := tx.NewSelect().Model(&rows).
+			Where("service = ? AND (id = ? OR url = ?)", r.Service, r.ID, r.URL).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+		}
+		for i := range rows {
+			existing[r.Service] = append(existing[r.Service], rows[i].record())
+		}
+	}
+	if err := checkDuplicates(existing, records); err != nil {
+		return err
+	}
+
+	rows := make([]*TClient, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, newTClient(r))
+	}
+	if _, err := tx.NewInsert().Model(&rows).Exec(ctx); err != nil {
+		return appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+	}
+	return nil
+}
+
+func (m *mysqlRegistry) Delete(ctx context.Context, service, key string) error {
+	res, err := m.db.NewDelete().Model((*TClient)(nil)).
+		Where("service = ? AND (id = ? OR url = ?)", service, key, trimURL(key)).
+		Exec(ctx)
+	if err != nil {
+		return appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return notFound(service, key)
+	}
+	return nil
+}
+
+func (m *mysqlRegistry) DeleteAll(ctx context.Context, service string) error {
+	_, err := m.db.NewDelete().Model((*TClient)(nil)).Where("service = ?", service).Exec(ctx)
+	if err != nil {
+		return appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+	}
+	return nil
+}
+
+func (m *mysqlRegistry) RecordResult(ctx context.Context, service, id string, at time.Time, errMsg string) error {
+	q := m.db.NewUpdate().Model((*TClient)(nil)).Where("service = ? AND id = ?", service, id)
+	if errMsg == "" {
+		q = q.Set("last_success = ?", at.UTC())
+	} else {
+		q = q.Set("last_failure = ?", at.UTC()).Set("last_error = ?", errMsg)
+	}
+
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return notFound(service, id)
+	}
+	return nil
+}
+
+func (m *mysqlRegistry) Seed(ctx context.Context, service string, records []Record) error {
+	return m.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// 여러 인스턴스가 동시에 시작해도 한 번만 등록 (먼저 insert한 인스턴스만 등록)
+		res, err := tx.NewInsert().Model(&TClientSeed{Service: service, SeededAt: time.Now().UTC()}).Ignore().Exec(ctx)
+		if err != nil {
+			return appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return nil
+		}
+
+		n, err := tx.NewSelect().Model((*TClient)(nil)).Where("service = ?", service).Count(ctx)
+		if err != nil {
+			return appErr.NewDBError(appErr.DB_QUERY_FAIL, "", err)
+		}
+		if n > 0 {
+			return nil
+		}
+		return addTx(ctx, tx, records)
+	})
+}
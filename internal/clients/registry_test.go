@@ -0,0 +1,158 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+)
+
+func testRegistry(t *testing.T, r Registry) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0).UTC()
+
+	err := r.Add(ctx, []Record{
+		{ID: "a", Service: "casb", URL: "http://127.0.0.1:5556", CreatedAt: now},
+		{ID: "b", Service: "casb", URL: "http://127.0.0.1:5557", Labels: map[string]string{"env": "prod"}, Secret: "s", CreatedAt: now.Add(time.Second)},
+		{ID: "a", Service: "ztna", URL: "http://127.0.0.1:5556", CreatedAt: now},
+	})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// id 또는 url이 하나라도 중복이면 모두 등록하지 않음
+	for _, dup := range []Record{
+		{ID: "c", Service: "casb", URL: "http://127.0.0.1:5556"},
+		{ID: "b", Service: "casb", URL: "http://127.0.0.1:5559"},
+	} {
+		err = r.Add(ctx, []Record{{ID: "d", Service: "casb", URL: "http://127.0.0.1:5558"}, dup})
+		if !errors.Is(err, appErr.ErrAlreadyRegistered) {
+			t.Fatalf("expected ErrAlreadyRegistered for %+v, got %v", dup, err)
+		}
+	}
+	records, err := r.Get(ctx, "casb")
+	if err != nil || len(records) != 2 || records[1].ID != "b" || records[1].Labels["env"] != "prod" || records[1].Secret != "s" {
+		t.Fatalf("unexpected records: %+v, %v", records, err)
+	}
+
+	failedAt := now.Add(time.Minute)
+	if err := r.RecordResult(ctx, "casb", "b", failedAt, "connection refused"); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := r.RecordResult(ctx, "casb", "b", failedAt.Add(time.Minute), ""); err != nil {
+		t.Fatalf("%v", err)
+	}
+	records, _ = r.Get(ctx, "casb")
+	if b := records[1]; b.LastFailure == nil || !b.LastFailure.Equal(failedAt) || b.LastError != "connection refused" || b.LastSuccess == nil {
+		t.Fatalf("unexpected delivery result: %+v", b)
+	}
+
+	// id 또는 url로 삭제
+	if err := r.Delete(ctx, "casb", "http://127.0.0.1:5556/"); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := r.Delete(ctx, "casb", "a"); !errors.Is(err, appErr.ErrClientNotFound) {
+		t.Fatalf("expected ErrClientNotFound, got %v", err)
+	}
+	if err := r.DeleteAll(ctx, "ztna"); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	all, err := r.List(ctx)
+	if err != nil || len(all["casb"]) != 1 || len(all["ztna"]) != 0 {
+		t.Fatalf("unexpected registry: %+v, %v", all, err)
+	}
+}
+
+func TestMemoryRegistry(t *testing.T) {
+	testRegistry(t, NewMemoryRegistry())
+}
+
+func TestFileRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	testRegistry(t, NewFileRegistry(path))
+
+	// 같은 파일을 사용하는 다른 인스턴스
+	records, err := NewFileRegistry(path).Get(context.Background(), "casb")
+	if err != nil || len(records) != 1 || records[0].ID != "b" {
+		t.Fatalf("unexpected records: %+v, %v", records, err)
+	}
+}
+
+func TestSeedRegistry(t *testing.T) {
+	for name, r := range map[string]Registry{
+		"memory": NewMemoryRegistry(),
+		"file":   NewFileRegistry(filepath.Join(t.TempDir(), "clients.json")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if err := r.Add(ctx, []Record{{ID: "registered", Service: "casb", URL: "http://registered"}}); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			seed := map[string][]string{
+				"casb": {"http://seed"},
+				"ztna": {"http://seed", "http://seed/", ""},
+				"test": {""},
+			}
+			if err := seedRegistry(ctx, r, seed, time.Now()); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			all, _ := r.List(ctx)
+			if len(all["casb"]) != 1 || all["casb"][0].ID != "registered" {
+				t.Fatalf("registered clients must not be overwritten by config: %+v", all["casb"])
+			}
+			if len(all["ztna"]) != 1 || all["ztna"][0].ID == "" || len(all["test"]) != 0 {
+				t.Fatalf("unexpected seeded clients: %+v", all)
+			}
+
+			// API로 삭제한 client는 재시작(seed) 후에도 다시 등록되지 않음
+			if err := r.DeleteAll(ctx, "casb"); err != nil {
+				t.Fatalf("%v", err)
+			}
+			if err := r.Delete(ctx, "ztna", "http://seed"); err != nil {
+				t.Fatalf("%v", err)
+			}
+			if err := seedRegistry(ctx, r, seed, time.Now()); err != nil {
+				t.Fatalf("%v", err)
+			}
+			all, _ = r.List(ctx)
+			if len(all["casb"]) != 0 || len(all["ztna"]) != 0 {
+				t.Fatalf("deleted clients must not be seeded again: %+v", all)
+			}
+		})
+	}
+}
+
+func TestRecordNormalize(t *testing.T) {
+	now := time.Now()
+	r := Record{URL: " http://opa:8181/ ", HookPath: "/opa/hooks", HookMethod: "put", Secret: "s"}
+	if err := r.normalize("casb", now); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if r.ID == "" || r.URL != "http://opa:8181" || r.HookMethod != "PUT" || !r.HasSecret {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+	if got := r.hookURL("hooks/bundle-update?type=delta"); got != "http://opa:8181/opa/hooks?type=delta" {
+		t.Fatalf("unexpected hook url %q", got)
+	}
+	if redacted := r.Redacted(); redacted.Secret != "" || !redacted.HasSecret {
+		t.Fatalf("secret must be redacted: %+v", redacted)
+	}
+
+	for _, bad := range []Record{
+		{URL: "opa:8181"},
+		{URL: "http://opa", ID: "../x"},
+		{URL: "http://opa", HookMethod: "DELETE"},
+		{URL: "http://opa", Labels: map[string]string{"bad key": "x"}},
+	} {
+		if err := bad.normalize("casb", now); !errors.Is(err, ErrInvalidClient) {
+			t.Fatalf("expected ErrInvalidClient for %+v, got %v", bad, err)
+		}
+	}
+}
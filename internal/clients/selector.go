@@ -0,0 +1,140 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidSelector = errors.New("invalid label selector")
+
+	labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,62})?$`)
+)
+
+type requirement struct {
+	key    string
+	op     string // "=" | "!=" | "exists" | "!exists"
+	values []string
+}
+
+// label selector (e.g. "env=prod,tenant!=a,region in (kr,jp),canary,!legacy")
+// 모든 조건을 만족해야 일치, 빈 selector는 모두 일치
+type Selector []requirement
+
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+	for _, part := range splitSelector(s) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var req requirement
+		switch {
+		case strings.Contains(part, "!="):
+			k, v, _ := strings.Cut(part, "!=")
+			req = requirement{key: k, op: "!=", values: []string{v}}
+		case strings.Contains(part, "="):
+			k, v, _ := strings.Cut(part, "=")
+			req = requirement{key: k, op: "=", values: []string{strings.TrimPrefix(v, "=")}}
+		case strings.Contains(part, " in ") || strings.Contains(part, " notin "):
+			op, sep := "=", " in "
+			if strings.Contains(part, " notin ") {
+				op, sep = "!=", " notin "
+			}
+			k, v, _ := strings.Cut(part, sep)
+			v = strings.TrimSpace(v)
+			if !strings.HasPrefix(v, "(") || !strings.HasSuffix(v, ")") {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidSelector, part)
+			}
+			req = requirement{key: k, op: op}
+			for _, val := range strings.Split(v[1:len(v)-1], ",") {
+				req.values = append(req.values, strings.TrimSpace(val))
+			}
+		case strings.HasPrefix(part, "!"):
+			req = requirement{key: part[1:], op: "!exists"}
+		default:
+			req = requirement{key: part, op: "exists"}
+		}
+
+		req.key = strings.TrimSpace(req.key)
+		for i := range req.values {
+			req.values[i] = strings.TrimSpace(req.values[i])
+		}
+		if !labelKeyPattern.MatchString(req.key) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSelector, part)
+		}
+		sel = append(sel, req)
+	}
+	return sel, nil
+}
+
+// "in (a,b)"의 ','는 구분자가 아님
+func splitSelector(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func (sel Selector) Matches(labels map[string]string) bool {
+	for _, req := range sel {
+		v, ok := labels[req.key]
+		switch req.op {
+		case "exists":
+			if !ok {
+				return false
+			}
+		case "!exists":
+			if ok {
+				return false
+			}
+		case "=":
+			if !ok || !contains(req.values, v) {
+				return false
+			}
+		case "!=":
+			if ok && contains(req.values, v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func contains(values []string, v string) bool {
+	for _, val := range values {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
+
+// selector와 일치하는 record
+func Filter(records []Record, sel Selector) []Record {
+	filtered := []Record{}
+	for _, r := range records {
+		if sel.Matches(r.Labels) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,40 @@
+package clients
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSelector(t *testing.T) {
+	labels := map[string]string{"env": "prod", "region": "kr", "canary": ""}
+
+	for s, want := range map[string]bool{
+		"":                           true,
+		"env=prod":                   true,
+		"env==prod,region=kr":        true,
+		"env!=dev":                   true,
+		"env=dev":                    false,
+		"region in (jp, kr)":         true,
+		"region notin (kr)":          false,
+		"canary":                     true,
+		"!legacy":                    true,
+		"!canary":                    false,
+		"tenant":                     false,
+		"tenant!=a":                  true,
+		"env=prod,region in (us,jp)": false,
+	} {
+		sel, err := ParseSelector(s)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q): %v", s, err)
+		}
+		if got := sel.Matches(labels); got != want {
+			t.Errorf("%q.Matches = %v, want %v", s, got, want)
+		}
+	}
+
+	for _, s := range []string{"region in kr", "=prod", "bad key=x"} {
+		if _, err := ParseSelector(s); !errors.Is(err, ErrInvalidSelector) {
+			t.Errorf("expected ErrInvalidSelector for %q, got %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package decision
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+const (
+	fileSuffix = ".ndjson"
+	dayLayout  = "2006-01-02"
+
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// OPA decision log event
+// https://www.openpolicyagent.org/docs/latest/management-decision-logs/
+type Event struct {
+	Labels      map[string]string         `json:"labels,omitempty"`
+	DecisionID  string                    `json:"decision_id"`
+	TraceID     string                    `json:"trace_id,omitempty"`
+	SpanID      string                    `json:"span_id,omitempty"`
+	Bundles     map[string]BundleRevision `json:"bundles,omitempty"`
+	Path        string                    `json:"path,omitempty"`
+	Query       string                    `json:"query,omitempty"`
+	Input       json.RawMessage           `json:"input,omitempty"`
+	Result      json.RawMessage           `json:"result,omitempty"`
+	Erased      []string                  `json:"erased,omitempty"`
+	Masked      []string                  `json:"masked,omitempty"`
+	Error       json.RawMessage           `json:"error,omitempty"`
+	RequestedBy string                    `json:"requested_by,omitempty"`
+	Timestamp   time.Time                 `json:"timestamp"`
+	Metrics     map[string]any            `json:"metrics,omitempty"`
+	ReqID       uint64                    `json:"req_id,omitempty"`
+}
+
+type BundleRevision struct {
+	Revision string `json:"revision"`
+}
+
+// event를 생성한 service
+// bundles에 포함된 service, 없으면 path의 첫 segment (e.g. casb/authz/allow => casb)
+func (e *Event) Services(isService func(string) bool) []string {
+	var services []string
+	for name := range e.Bundles {
+		if isService(name) {
+			services = append(services, name)
+		}
+	}
+	if len(services) == 0 {
+		first, _, _ := strings.Cut(strings.Trim(e.Path, "/"), "/")
+		if first != "" && isService(first) {
+			services = append(services, first)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// 조회 조건 (zero value인 조건은 무시)
+type Query struct {
+	From       time.Time
+	To         time.Time
+	DecisionID string
+	Path       string          // 같은 경로 또는 하위 경로 (e.g. casb => casb/authz/allow)
+	Result     json.RawMessage // JSON 값 비교 (e.g. false)
+	Limit      int
+}
+
+// service별 decision log 저장소 (<dir>/<service>/YYYY-MM-DD.ndjson, UTC 기준 일 단위 rotation)
+type Store struct {
+	dir      string
+	keepDays int // 0이면 삭제하지 않음
+
+	mu sync.Mutex
+}
+
+func NewStore(dir string, keepDays int) *Store {
+	return &Store{dir: dir, keepDays: keepDays}
+}
+
+// event를 timestamp의 날짜 파일에 추가
+func (s *Store) Append(ctx context.Context, service string, events []Event) error {
+	byDay := map[string][]Event{}
+	for _, e := range events {
+		if e.Timestamp.IsZero() {
+			e.Timestamp = time.Now()
+		}
+		day := e.Timestamp.UTC().Format(dayLayout)
+		byDay[day] = append(byDay[day], e)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.dir, service)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for day, events := range byDay {
+		buf := new(bytes.Buffer)
+		enc := json.NewEncoder(buf)
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return fmt.Errorf("failed to encode decision %s: %w", e.DecisionID, err)
+			}
+		}
+		if err := appendFile(ctx, filepath.Join(dir, day+fileSuffix), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return s.sweep(dir, time.Now())
+}
+
+// 조건에 맞는 event (최신순, 최대 Limit개)
+func (s *Store) Search(service string, q Query) ([]Event, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	q.Limit = min(q.Limit, MaxLimit)
+
+	var result any
+	if len(q.Result) > 0 {
+		if err := json.Unmarshal(q.Result, &result); err != nil {
+			return nil, fmt.Errorf("invalid result filter: %w", err)
+		}
+	}
+
+	days, err := s.days(filepath.Join(s.dir, service))
+	if err != nil {
+		return nil, err
+	}
+
+	found := []Event{}
+	for i := len(days) - 1; i >= 0 && len(found) < q.Limit; i-- {
+		day := days[i]
+		if !q.From.IsZero() && day.Before(q.From.UTC().Truncate(24*time.Hour)) {
+			break
+		}
+		if !q.To.IsZero() && day.After(q.To.UTC()) {
+			continue
+		}
+
+		events, err := readFile(filepath.Join(s.dir, service, day.Format(dayLayout)+fileSuffix))
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Timestamp.After(events[j].Timestamp)
+		})
+
+		for _, e := range events {
+			if q.match(&e, result) {
+				found = append(found, e)
+				if len(found) == q.Limit {
+					break
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+func (q *Query) match(e *Event, result any) bool {
+	if !q.From.IsZero() && e.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && e.Timestamp.After(q.To) {
+		return false
+	}
+	if q.DecisionID != "" && e.DecisionID != q.DecisionID {
+		return false
+	}
+	if q.Path != "" {
+		want, got := strings.Trim(q.Path, "/"), strings.Trim(e.Path, "/")
+		if got != want && !strings.HasPrefix(got, want+"/") {
+			return false
+		}
+	}
+	if len(q.Result) > 0 {
+		var v any
+		if len(e.Result) == 0 || json.Unmarshal(e.Result, &v) != nil || !jsonEqual(v, result) {
+			return false
+		}
+	}
+	return true
+}
+
+// 보관 기간이 지난 파일 삭제 (caller가 s.mu를 잡고 있어야 함)
+func (s *Store) sweep(dir string, now time.Time) error {
+	if s.keepDays <= 0 {
+		return nil
+	}
+
+	days, err := s.days(dir)
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -s.keepDays)
+	var errs []error
+	for _, day := range days {
+		if !day.Before(cutoff) {
+			break
+		}
+		path := filepath.Join(dir, day.Format(dayLayout)+fileSuffix)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		os.Remove(path + ".lock")
+	}
+	return errors.Join(errs...)
+}
+
+// dir의 decision log 날짜 (오래된 순)
+func (s *Store) days(dir string) ([]time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var days []time.Time
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), fileSuffix)
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		day, err := time.Parse(dayLayout, name)
+		if err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+func appendFile(ctx context.Context, path string, data []byte) error {
+	fileLock := flock.New(path + ".lock") // 멀티 프로세스 환경에서의 쓰기 충돌 방지
+	locked, err := fileLock.TryLockContext(ctx, time.Millisecond*500)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !locked {
+		return fmt.Errorf("timeout: could not acquire file lock")
+	}
+	defer fileLock.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readFile(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // 쓰기 도중 중단된 줄
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return events, nil
+}
+
+func jsonEqual(a, b any) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,86 @@
+package decision
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 0)
+
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	events := []Event{
+		{DecisionID: "1", Path: "casb/authz/allow", Result: json.RawMessage(`true`), Timestamp: yesterday},
+		{DecisionID: "2", Path: "casb/authz/allow", Result: json.RawMessage(`false`), Timestamp: now.Add(-time.Minute)},
+		{DecisionID: "3", Path: "casb/audit", Result: json.RawMessage(`{"deny": false}`), Timestamp: now},
+	}
+	if err := s.Append(context.Background(), "casb", events); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		q    Query
+		want []string
+	}{
+		{"all (newest first)", Query{}, []string{"3", "2", "1"}},
+		{"limit", Query{Limit: 1}, []string{"3"}},
+		{"decision id", Query{DecisionID: "2"}, []string{"2"}},
+		{"path prefix", Query{Path: "casb/authz"}, []string{"2", "1"}},
+		{"result", Query{Result: json.RawMessage(`false`)}, []string{"2"}},
+		{"object result", Query{Result: json.RawMessage(`{"deny":false}`)}, []string{"3"}},
+		{"time range", Query{From: now.Add(-time.Hour), To: now.Add(-time.Second)}, []string{"2"}},
+	} {
+		got, err := s.Search("casb", tc.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var ids []string
+		for _, e := range got {
+			ids = append(ids, e.DecisionID)
+		}
+		if len(ids) != len(tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, ids)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tc.want[i] {
+				t.Errorf("%s: expected %v, got %v", tc.name, tc.want, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestStoreSweep(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 2)
+
+	old := time.Now().UTC().AddDate(0, 0, -5)
+	if err := s.Append(context.Background(), "casb", []Event{{DecisionID: "old", Timestamp: old}}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "casb", old.Format(dayLayout)+fileSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("expected expired decision log to be removed, got %v", err)
+	}
+}
+
+func TestEventServices(t *testing.T) {
+	isService := func(name string) bool { return name == "casb" }
+
+	e := Event{Bundles: map[string]BundleRevision{"casb": {Revision: "v0.3"}, "other": {}}}
+	if got := e.Services(isService); len(got) != 1 || got[0] != "casb" {
+		t.Fatalf("unexpected services: %v", got)
+	}
+
+	e = Event{Path: "/casb/authz/allow"}
+	if got := e.Services(isService); len(got) != 1 || got[0] != "casb" {
+		t.Fatalf("unexpected services: %v", got)
+	}
+}
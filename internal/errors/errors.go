@@ -17,6 +17,7 @@ var (
 	ErrNoChanges             = errors.New("no changes detected")
 	ErrSendEventNotification = errors.New("send event notification failed")
 	ErrAlreadyRegistered     = errors.New("client already registered")
+	ErrClientNotFound        = errors.New("client not found")
 )
 
 func HandleError(c *gin.Context, logger *zap.Logger, httpErr HttpError, msg string, fields ...zap.Field) {
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	TypeBundlePublished = "bundle-published"
+	TypeSync            = "sync" // Last-Event-ID 이후 event가 buffer에 없는 경우 현재 최신 bundle
+
+	DefaultBufferSize = 256
+	subscriberBuffer  = 16
+)
+
+type Event struct {
+	ID         int64     `json:"id"`
+	Service    string    `json:"service"`
+	BundleType string    `json:"type"` // "regular" | "delta"
+	Version    string    `json:"version"`
+	ETag       string    `json:"etag"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+type topic struct {
+	events []Event // 최근 event (최대 size개, 오래된 순)
+	subs   map[chan Event]struct{}
+}
+
+// service별 bundle event 발행/구독 (SSE)
+type Broker struct {
+	size     int
+	lastID   int64
+	services map[string]*topic
+	mu       sync.Mutex
+}
+
+func NewBroker(size int) *Broker {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Broker{size: size, services: make(map[string]*topic)}
+}
+
+// caller가 mu를 잡고 호출
+func (b *Broker) topic(service string) *topic {
+	t, ok := b.services[service]
+	if !ok {
+		t = &topic{subs: make(map[chan Event]struct{})}
+		b.services[service] = t
+	}
+	return t
+}
+
+// 재시작 후에도 증가하도록 시각 기반 ID (μs)
+func (b *Broker) nextID(now time.Time) int64 {
+	id := now.UnixMicro()
+	if id <= b.lastID {
+		id = b.lastID + 1
+	}
+	b.lastID = id
+	return id
+}
+
+// ID를 부여하고 구독자에게 전달 (buffer가 가득 찬 구독자는 연결 종료, Last-Event-ID로 재연결)
+func (b *Broker) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	e.ID = b.nextID(e.Timestamp)
+
+	t := b.topic(e.Service)
+	t.events = append(t.events, e)
+	if len(t.events) > b.size {
+		t.events = t.events[len(t.events)-b.size:]
+	}
+
+	for ch := range t.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(t.subs, ch)
+			close(ch)
+		}
+	}
+	return e
+}
+
+// lastID 이후 buffer에 남아있는 event와 이후 event를 받을 channel
+// lastID 이후 event가 buffer에서 이미 제거되었거나 알 수 없는 경우(e.g. 재시작) gap
+func (b *Broker) Subscribe(service string, lastID int64) (backlog []Event, gap bool, ch <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topic(service)
+	if lastID > 0 {
+		gap = !b.contains(t, lastID) && (len(t.events) == 0 || lastID < t.events[0].ID)
+		for _, e := range t.events {
+			if e.ID > lastID {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+
+	c := make(chan Event, subscriberBuffer)
+	t.subs[c] = struct{}{}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := t.subs[c]; ok {
+			delete(t.subs, c)
+			close(c)
+		}
+	}
+	return backlog, gap, c, cancel
+}
+
+func (b *Broker) contains(t *topic, id int64) bool {
+	for _, e := range t.events {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// 현재 구독자 수
+func (b *Broker) Subscribers(service string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.topic(service).subs)
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBroker(t *testing.T) {
+	b := NewBroker(2)
+	now := time.Unix(1700000000, 0)
+
+	_, gap, ch, cancel := b.Subscribe("casb", 0)
+	if gap {
+		t.Fatalf("new subscriber without Last-Event-ID must not have a gap")
+	}
+
+	e1 := b.Publish(Event{Service: "casb", Version: "v0.1", Timestamp: now})
+	e2 := b.Publish(Event{Service: "casb", Version: "v0.2", Timestamp: now}) // 같은 시각이어도 ID 증가
+	b.Publish(Event{Service: "ztna", Version: "v0.1", Timestamp: now})
+	if e2.ID <= e1.ID {
+		t.Fatalf("event IDs must increase: %d, %d", e1.ID, e2.ID)
+	}
+	for _, want := range []string{"v0.1", "v0.2"} {
+		select {
+		case e := <-ch:
+			if e.Version != want || e.Service != "casb" {
+				t.Fatalf("unexpected event: %+v", e)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %s not delivered", want)
+		}
+	}
+	cancel()
+	cancel() // 중복 호출 허용
+	if n := b.Subscribers("casb"); n != 0 {
+		t.Fatalf("expected no subscribers, got %d", n)
+	}
+
+	// resume
+	backlog, gap, _, cancel := b.Subscribe("casb", e1.ID)
+	cancel()
+	if gap || len(backlog) != 1 || backlog[0].ID != e2.ID {
+		t.Fatalf("unexpected resume: %+v, gap=%v", backlog, gap)
+	}
+
+	// buffer(2개)에서 제거된 event 이후부터 resume: gap
+	e3 := b.Publish(Event{Service: "casb", Version: "v0.3", Timestamp: now})
+	backlog, gap, _, cancel = b.Subscribe("casb", e1.ID)
+	cancel()
+	if !gap || len(backlog) != 2 || backlog[1].ID != e3.ID {
+		t.Fatalf("expected gap with buffered events, got %+v, gap=%v", backlog, gap)
+	}
+
+	// 재시작 등으로 buffer가 비어있는 경우: gap
+	backlog, gap, _, cancel = NewBroker(2).Subscribe("casb", e3.ID)
+	cancel()
+	if !gap || len(backlog) != 0 {
+		t.Fatalf("expected gap after restart, got %+v, gap=%v", backlog, gap)
+	}
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	b := NewBroker(0)
+	_, _, ch, cancel := b.Subscribe("casb", 0)
+	defer cancel()
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish(Event{Service: "casb"})
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Fatalf("slow subscriber must be disconnected after %d events, got %d", subscriberBuffer, n)
+	}
+}
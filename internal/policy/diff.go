@@ -0,0 +1,171 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-', '+'
+	line string
+}
+
+// 두 정책 snapshot의 unified diff (git diff와 같은 형식, 변경된 파일만 포함)
+// fromLabel, toLabel은 파일명 앞에 붙는 접두사 (e.g. "rev-1")
+func UnifiedDiff(from, to map[string][]byte, fromLabel, toLabel string) string {
+	names := map[string]bool{}
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	for _, name := range sorted {
+		a, inFrom := from[name]
+		b, inTo := to[name]
+		if inFrom && inTo && bytes.Equal(a, b) {
+			continue
+		}
+
+		fromName, toName := fromLabel+"/"+name, toLabel+"/"+name
+		if !inFrom {
+			fromName = "/dev/null"
+		}
+		if !inTo {
+			toName = "/dev/null"
+		}
+		fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+		writeHunks(&buf, diffLines(splitLines(a), splitLines(b)))
+	}
+	return buf.String()
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// LCS 기반 줄 단위 diff
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// 변경 줄 앞뒤 diffContext줄을 포함하는 hunk로 출력
+func writeHunks(buf *bytes.Buffer, ops []diffOp) {
+	for start := 0; start < len(ops); {
+		// 다음 변경 위치
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			return
+		}
+
+		// 변경 사이의 공통 줄이 2*diffContext 이하인 경우 같은 hunk로 묶음
+		last := first
+		for k := first; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				last = k
+				continue
+			}
+			if k-last > 2*diffContext {
+				break
+			}
+		}
+
+		lo := max(first-diffContext, 0)
+		hi := min(last+diffContext+1, len(ops))
+
+		// hunk 시작 줄 번호 (1부터)
+		aLine, bLine := 1, 1
+		for _, op := range ops[:lo] {
+			if op.kind != '+' {
+				aLine++
+			}
+			if op.kind != '-' {
+				bLine++
+			}
+		}
+		var aCount, bCount int
+		for _, op := range ops[lo:hi] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		if aCount == 0 {
+			aLine--
+		}
+		if bCount == 0 {
+			bLine--
+		}
+
+		fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		for _, op := range ops[lo:hi] {
+			buf.WriteByte(op.kind)
+			buf.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = hi
+	}
+}
@@ -0,0 +1,36 @@
+package policy
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	from := map[string][]byte{
+		"policy.rego":    []byte("package a\n\nallow := false\n"),
+		"lib/old.rego":   []byte("package a.old\n"),
+		"same_test.rego": []byte("package a_test\n"),
+	}
+	to := map[string][]byte{
+		"policy.rego":    []byte("package a\n\nallow := true\n"),
+		"lib/new.rego":   []byte("package a.new\n"),
+		"same_test.rego": []byte("package a_test\n"),
+	}
+
+	want := `--- /dev/null
++++ rev-2/lib/new.rego
+@@ -0,0 +1,1 @@
++package a.new
+--- rev-1/lib/old.rego
++++ /dev/null
+@@ -1,1 +0,0 @@
+-package a.old
+--- rev-1/policy.rego
++++ rev-2/policy.rego
+@@ -1,3 +1,3 @@
+ package a
+ 
+-allow := false
++allow := true
+`
+	if got := UnifiedDiff(from, to, "rev-1", "rev-2"); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
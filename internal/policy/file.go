@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidPath = errors.New("invalid policy path")
+
+// 업로드된 정책 파일의 상대 경로 검사 및 정리 (e.g. "./lib/util.rego" => "lib/util.rego")
+func CleanPath(p string) (string, error) {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if p == "" || path.IsAbs(p) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPath, p)
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidPath, p)
+		}
+	}
+
+	cleaned := path.Clean(p)
+	if path.Ext(cleaned) != ".rego" {
+		return "", fmt.Errorf("%w: only .rego files are allowed: %q", ErrInvalidPath, p)
+	}
+	return cleaned, nil
+}
+
+// dir 하위의 모든 .rego 파일 (dir 기준 상대경로 => 내용), dir이 없는 경우 빈 map
+func ReadModules(dir string) (map[string][]byte, error) {
+	modules := map[string][]byte{}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && p == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() || filepath.Ext(p) != ".rego" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		modules[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy modules: %w", err)
+	}
+
+	return modules, nil
+}
@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"policy.rego":          "policy.rego",
+		"./lib/util.rego":      "lib/util.rego",
+		"lib//authz_test.rego": "lib/authz_test.rego",
+	} {
+		got, err := CleanPath(in)
+		if err != nil || got != want {
+			t.Errorf("CleanPath(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "/etc/policy.rego", "../policy.rego", "lib/../../x.rego", "data.json"} {
+		if _, err := CleanPath(in); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("expected ErrInvalidPath for %q, got %v", in, err)
+		}
+	}
+}
+
+func TestReadModules(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"policy.rego":   "package a",
+		"lib/util.rego": "package a.lib",
+		"data.json":     "{}",
+	} {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("%v", err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	modules, err := ReadModules(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(modules) != 2 || string(modules["lib/util.rego"]) != "package a.lib" {
+		t.Fatalf("unexpected modules: %v", modules)
+	}
+
+	modules, err = ReadModules(filepath.Join(dir, "missing"))
+	if err != nil || len(modules) != 0 {
+		t.Fatalf("expected empty modules, got %v, %v", modules, err)
+	}
+}
@@ -0,0 +1,180 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"github.com/jjhwan-h/bundle-server/internal/utils"
+)
+
+const (
+	HistoryIndexFile = "index.json"
+
+	ActionImport  = "import"  // history 도입 이전부터 존재하던 정책
+	ActionUpload  = "upload"  // POST /services/:service/policy
+	ActionDelete  = "delete"  // DELETE /services/:service/policy/*path
+	ActionRestore = "restore" // POST /services/:service/policy/restore
+)
+
+var ErrRevisionNotFound = errors.New("policy revision not found")
+
+// 정책 변경 1건 (변경 후 전체 정책 snapshot을 <rev>.tar.gz로 보관)
+type Revision struct {
+	Revision     int64     `json:"revision"`
+	Action       string    `json:"action"`
+	Author       string    `json:"author"`
+	CreatedAt    time.Time `json:"created_at"`
+	SHA256       string    `json:"sha256"` // snapshot 해시
+	Files        []string  `json:"files"`  // 변경된 파일
+	RestoredFrom int64     `json:"restored_from,omitempty"`
+}
+
+// service별 정책 변경 이력 (<opa_data_path>/<service>/history)
+// 기록된 revision과 snapshot은 수정하지 않음
+type History struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewHistory(dir string) *History {
+	return &History{dir: dir}
+}
+
+// 이력이 비어있고 현재 정책이 있는 경우 변경 전 상태를 import revision으로 기록
+func (h *History) Init(ctx context.Context, files map[string][]byte) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	revs, err := h.load()
+	if err != nil || len(revs) > 0 {
+		return err
+	}
+	_, err = h.record(ctx, revs, Revision{Action: ActionImport, Author: "system", Files: sortedKeys(files)}, files)
+	return err
+}
+
+// 변경 후 전체 정책(module, test 파일)을 새 revision으로 기록
+func (h *History) Record(ctx context.Context, rev Revision, files map[string][]byte) (*Revision, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	revs, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+	return h.record(ctx, revs, rev, files)
+}
+
+// 기록된 revision (오래된 순)
+func (h *History) List() ([]Revision, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.load()
+}
+
+// revision 시점의 전체 정책 (상대경로 => 내용)
+func (h *History) Snapshot(rev int64) (map[string][]byte, error) {
+	raw, err := os.ReadFile(filepath.Join(h.dir, snapshotFileName(rev)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, rev)
+		}
+		return nil, fmt.Errorf("failed to read policy snapshot: %w", err)
+	}
+
+	files, err := bundle.ReadTarGz(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("revision %d: %w", rev, err)
+	}
+
+	snapshot := make(map[string][]byte, len(files))
+	for _, f := range files {
+		snapshot[f.Name] = f.Data
+	}
+	return snapshot, nil
+}
+
+// caller가 h.mu를 잡고 있어야 함
+func (h *History) record(ctx context.Context, revs []Revision, rev Revision, files map[string][]byte) (*Revision, error) {
+	buf := new(bytes.Buffer)
+	names := sortedKeys(files)
+	snapshot := make([]bundle.File, 0, len(names))
+	for _, name := range names {
+		snapshot = append(snapshot, bundle.File{Name: name, Data: files[name]})
+	}
+	if err := bundle.WriteTarGz(buf, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to archive policy snapshot: %w", err)
+	}
+
+	rev.Revision = 1
+	if len(revs) > 0 {
+		rev.Revision = revs[len(revs)-1].Revision + 1
+	}
+	rev.SHA256 = hashSnapshot(snapshot)
+	if rev.CreatedAt.IsZero() {
+		rev.CreatedAt = time.Now()
+	}
+	sort.Strings(rev.Files)
+
+	// snapshot을 먼저 저장해 index에는 항상 존재하는 revision만 기록
+	if err := utils.SaveToFileWithLock(ctx, buf, filepath.Join(h.dir, snapshotFileName(rev.Revision))); err != nil {
+		return nil, fmt.Errorf("failed to save policy snapshot: %w", err)
+	}
+
+	buf.Reset()
+	if err := utils.EncodeJson(buf, append(revs, rev)); err != nil {
+		return nil, fmt.Errorf("failed to encode policy history: %w", err)
+	}
+	if err := utils.SaveToFileWithLock(ctx, buf, filepath.Join(h.dir, HistoryIndexFile)); err != nil {
+		return nil, fmt.Errorf("failed to save policy history: %w", err)
+	}
+
+	return &rev, nil
+}
+
+// caller가 h.mu를 잡고 있어야 함
+func (h *History) load() ([]Revision, error) {
+	raw, err := os.ReadFile(filepath.Join(h.dir, HistoryIndexFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read policy history: %w", err)
+	}
+
+	var revs []Revision
+	if err := json.Unmarshal(raw, &revs); err != nil {
+		return nil, fmt.Errorf("failed to decode policy history: %w", err)
+	}
+	return revs, nil
+}
+
+func snapshotFileName(rev int64) string {
+	return fmt.Sprintf("%d.tar.gz", rev)
+}
+
+// 파일명과 내용 기준 해시 (tar header의 시간과 무관)
+func hashSnapshot(files []bundle.File) string {
+	h := sha256.New()
+	for _, f := range files {
+		sum := sha256.Sum256(f.Data)
+		fmt.Fprintf(h, "%s %s\n", hex.EncodeToString(sum[:]), f.Name)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
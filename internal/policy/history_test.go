@@ -0,0 +1,49 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	h := NewHistory(t.TempDir())
+
+	if err := h.Init(ctx, map[string][]byte{"policy.rego": []byte("package a\n")}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	rev, err := h.Record(ctx, Revision{Action: ActionUpload, Author: "alice", Files: []string{"policy.rego"}},
+		map[string][]byte{"policy.rego": []byte("package a\n\nallow := true\n")})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if rev.Revision != 2 || rev.SHA256 == "" {
+		t.Fatalf("unexpected revision: %+v", rev)
+	}
+
+	// 이력이 있으면 Init은 기록하지 않음
+	if err := h.Init(ctx, map[string][]byte{"other.rego": []byte("package b\n")}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	revs, err := h.List()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(revs) != 2 || revs[0].Action != ActionImport || revs[1].Author != "alice" {
+		t.Fatalf("unexpected history: %+v", revs)
+	}
+
+	snapshot, err := h.Snapshot(1)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(snapshot["policy.rego"]) != "package a\n" {
+		t.Fatalf("unexpected snapshot: %q", snapshot["policy.rego"])
+	}
+
+	if _, err := h.Snapshot(3); !errors.Is(err, ErrRevisionNotFound) {
+		t.Fatalf("expected ErrRevisionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/open-policy-agent/opa/util"
+)
+
+const testPrefix = "test_"
+
+// 실패한 rego test rule
+type TestFailure struct {
+	Name    string `json:"name"` // e.g. data.casb.authz_test.test_allow
+	File    string `json:"file"`
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type TestError struct {
+	Failures []TestFailure
+}
+
+func (e *TestError) Error() string {
+	names := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		names = append(names, f.Name)
+	}
+	return fmt.Sprintf("%d test(s) failed: %s", len(e.Failures), strings.Join(names, ", "))
+}
+
+func IsTestFile(name string) bool {
+	return strings.HasSuffix(name, "_test.rego")
+}
+
+// bundle 파일(.rego, data.json)을 embedded OPA에 적재하고 tests(파일명 => rego)의 test_ rule 실행
+// test rule이 true가 아닌 경우(false, undefined, 평가 오류) TestError 반환
+func RunTests(ctx context.Context, files []bundle.File, tests map[string][]byte) error {
+	if len(tests) == 0 {
+		return nil
+	}
+
+	sources := map[string][]byte{}
+	data := map[string]any{}
+	for _, f := range files {
+		switch {
+		case path.Ext(f.Name) == ".rego":
+			sources[f.Name] = f.Data
+		case path.Base(f.Name) == "data.json":
+			var v any
+			if err := util.UnmarshalJSON(f.Data, &v); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", f.Name, err)
+			}
+			if err := mergeData(data, path.Dir(f.Name), v); err != nil {
+				return err
+			}
+		}
+	}
+
+	testNames := make([]string, 0, len(tests))
+	for name, src := range tests {
+		name = path.Join("tests", name)
+		sources[name] = src
+		testNames = append(testNames, name)
+	}
+	sort.Strings(testNames)
+
+	modules := make(map[string]*ast.Module, len(sources))
+	for name, src := range sources {
+		m, err := ast.ParseModule(name, string(src))
+		if err != nil {
+			return &ValidationError{Diagnostics: toDiagnostics(name, err)}
+		}
+		if m != nil {
+			modules[name] = m
+		}
+	}
+
+	compiler := ast.NewCompiler()
+	compiler.Compile(modules)
+	if compiler.Failed() {
+		return &ValidationError{Diagnostics: toDiagnostics("", compiler.Errors)}
+	}
+
+	store := inmem.NewFromObject(data)
+
+	var failures []TestFailure
+	for _, name := range testNames {
+		m, ok := modules[name]
+		if !ok {
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, rule := range m.Rules {
+			ruleName := rule.Head.Name.String()
+			if !strings.HasPrefix(ruleName, testPrefix) || seen[ruleName] {
+				continue
+			}
+			seen[ruleName] = true
+
+			query := m.Package.Path.String() + "." + ruleName
+			if msg := evalTest(ctx, compiler, store, query); msg != "" {
+				failures = append(failures, TestFailure{
+					Name:    query,
+					File:    strings.TrimPrefix(name, "tests/"),
+					Row:     rule.Location.Row,
+					Message: msg,
+				})
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return &TestError{Failures: failures}
+	}
+	return nil
+}
+
+// 성공 시 빈 문자열, 실패 시 사유
+func evalTest(ctx context.Context, compiler *ast.Compiler, store storage.Store, query string) string {
+	rs, err := rego.New(
+		rego.Compiler(compiler),
+		rego.Store(store),
+		rego.Query(query),
+	).Eval(ctx)
+	if err != nil {
+		return err.Error()
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return "undefined"
+	}
+	if v, ok := rs[0].Expressions[0].Value.(bool); !ok || !v {
+		return fmt.Sprintf("fail: %v", rs[0].Expressions[0].Value)
+	}
+	return ""
+}
+
+// "a/b" => data["a"]["b"]에 value 병합
+func mergeData(data map[string]any, dir string, value any) error {
+	node := data
+	if dir != "." && dir != "" {
+		for _, key := range strings.Split(dir, "/") {
+			child, ok := node[key].(map[string]any)
+			if !ok {
+				if _, exists := node[key]; exists {
+					return fmt.Errorf("data path %s conflicts with a non-object value", dir)
+				}
+				child = map[string]any{}
+				node[key] = child
+			}
+			node = child
+		}
+	}
+
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("data.json under %s must be an object", dir)
+	}
+	for k, v := range obj {
+		node[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jjhwan-h/bundle-server/internal/bundle"
+)
+
+func TestRunTests(t *testing.T) {
+	files := []bundle.File{
+		{Name: bundle.ManifestFile, Data: []byte(`{"revision":"v0.1"}`)},
+		{Name: "data.json", Data: []byte(`{"casb":{"default_effect":"deny"}}`)},
+		{Name: "policy.rego", Data: []byte("package casb.authz\n\ndefault allow = false\n\nallow {\n\tdata.casb.default_effect == \"allow\"\n}\n")},
+	}
+
+	passing := map[string][]byte{
+		"authz_test.rego": []byte("package casb.authz_test\n\ntest_default_deny {\n\tnot data.casb.authz.allow\n}\n"),
+	}
+	if err := RunTests(context.Background(), files, passing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failing := map[string][]byte{
+		"authz_test.rego": []byte("package casb.authz_test\n\ntest_default_deny {\n\tnot data.casb.authz.allow\n}\n\ntest_allow {\n\tdata.casb.authz.allow\n}\n\ntest_false = false\n"),
+	}
+	err := RunTests(context.Background(), files, failing)
+
+	var terr *TestError
+	if !errors.As(err, &terr) {
+		t.Fatalf("expected TestError, got %v", err)
+	}
+	if len(terr.Failures) != 2 {
+		t.Fatalf("unexpected failures: %+v", terr.Failures)
+	}
+	if f := terr.Failures[0]; f.Name != "data.casb.authz_test.test_allow" || f.File != "authz_test.rego" || f.Row != 7 {
+		t.Fatalf("unexpected failure: %+v", f)
+	}
+
+	invalid := map[string][]byte{
+		"authz_test.rego": []byte("package casb.authz_test\n\ntest_x {\n\tx == 1\n}\n"),
+	}
+	var verr *ValidationError
+	if err := RunTests(context.Background(), files, invalid); !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if err := RunTests(context.Background(), files, nil); err != nil {
+		t.Fatalf("expected no error without tests, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// rego 파싱/컴파일 진단 정보
+type Diagnostic struct {
+	File    string `json:"file"`
+	Row     int    `json:"row"`
+	Col     int    `json:"col"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		msgs = append(msgs, fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Row, d.Col, d.Code, d.Message))
+	}
+	return fmt.Sprintf("%d error(s) occurred: %s", len(e.Diagnostics), strings.Join(msgs, "; "))
+}
+
+// modules(파일명 => rego)를 파싱/컴파일하여 OPA client에서 bundle 활성화가 실패할 정책을 사전에 거부
+//   - data가 있는 경우 data.json과 경로가 겹치는 rule 검사
+//   - roots가 비어있지 않은 경우 roots 밖의 package 거부
+func Validate(modules map[string][]byte, data map[string]any, roots []string) error {
+	var diags []Diagnostic
+
+	parsed := make(map[string]*ast.Module, len(modules))
+	for _, name := range sortedKeys(modules) {
+		m, err := ast.ParseModule(name, string(modules[name]))
+		if err != nil {
+			diags = append(diags, toDiagnostics(name, err)...)
+			continue
+		}
+		if m == nil {
+			diags = append(diags, Diagnostic{File: name, Row: 1, Col: 1, Code: ast.ParseErr, Message: "empty module"})
+			continue
+		}
+
+		if path := packagePath(m); !inRoots(path, roots) {
+			loc := m.Package.Location
+			diags = append(diags, Diagnostic{
+				File:    name,
+				Row:     loc.Row,
+				Col:     loc.Col,
+				Code:    "bundle_root_error",
+				Message: fmt.Sprintf("package %s is outside of the bundle roots %v", m.Package.Path, roots),
+			})
+			continue
+		}
+		parsed[name] = m
+	}
+	if len(diags) > 0 {
+		return &ValidationError{Diagnostics: diags}
+	}
+
+	compiler := ast.NewCompiler()
+	compiler.Compile(parsed)
+	if compiler.Failed() {
+		return &ValidationError{Diagnostics: toDiagnostics("", compiler.Errors)}
+	}
+
+	if data != nil {
+		if errs := ast.CheckPathConflicts(compiler, dataExists(data)); len(errs) > 0 {
+			return &ValidationError{Diagnostics: toDiagnostics("", errs)}
+		}
+	}
+
+	return nil
+}
+
+func toDiagnostics(file string, err error) []Diagnostic {
+	var astErrs ast.Errors
+	if !errors.As(err, &astErrs) {
+		return []Diagnostic{{File: file, Row: 1, Col: 1, Code: ast.ParseErr, Message: err.Error()}}
+	}
+
+	diags := make([]Diagnostic, 0, len(astErrs))
+	for _, e := range astErrs {
+		d := Diagnostic{File: file, Code: e.Code, Message: e.Message}
+		if e.Location != nil {
+			d.Row, d.Col = e.Location.Row, e.Location.Col
+			if e.Location.File != "" {
+				d.File = e.Location.File
+			}
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// data.casb.authz => casb/authz
+func packagePath(m *ast.Module) string {
+	parts := make([]string, 0, len(m.Package.Path)-1)
+	for _, term := range m.Package.Path[1:] {
+		s, ok := term.Value.(ast.String)
+		if !ok {
+			parts = append(parts, term.String())
+			continue
+		}
+		parts = append(parts, string(s))
+	}
+	return strings.Join(parts, "/")
+}
+
+func inRoots(path string, roots []string) bool {
+	if len(roots) == 0 {
+		return true
+	}
+	for _, root := range roots {
+		root = strings.Trim(root, "/")
+		if root == "" || path == root || strings.HasPrefix(path, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// storage.NonEmpty와 동일: 경로에 값이 있거나, 상위 경로가 object가 아닌 값인 경우 true
+func dataExists(data map[string]any) func([]string) (bool, error) {
+	return func(path []string) (bool, error) {
+		var node any = data
+		for _, key := range path {
+			obj, ok := node.(map[string]any)
+			if !ok {
+				return true, nil
+			}
+			node, ok = obj[key]
+			if !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	data := map[string]any{
+		"casb": map[string]any{
+			"policies":       []any{},
+			"default_effect": "deny",
+		},
+	}
+
+	cases := []struct {
+		name  string
+		src   string
+		roots []string
+		code  string
+		row   int
+	}{
+		{
+			name: "valid",
+			src:  "package casb.authz\n\ndefault allow = false\n\nallow {\n\tdata.casb.default_effect == \"allow\"\n}\n",
+		},
+		{
+			name: "syntax error",
+			src:  "package casb.authz\n\nallow {\n\tinput.user ==\n}\n",
+			code: "rego_parse_error",
+			row:  5,
+		},
+		{
+			name: "unsafe variable",
+			src:  "package casb.authz\n\nallow {\n\tx == 1\n}\n",
+			code: "rego_unsafe_var_error",
+			row:  4,
+		},
+		{
+			name: "conflict with data.json",
+			src:  "package casb\n\ndefault_effect = \"allow\"\n",
+			code: "rego_compile_error",
+			row:  3,
+		},
+		{
+			name:  "outside roots",
+			src:   "package ztna.authz\n\nallow = true\n",
+			roots: []string{"casb"},
+			code:  "bundle_root_error",
+			row:   1,
+		},
+		{
+			name:  "inside roots",
+			src:   "package casb.authz\n\nallow = true\n",
+			roots: []string{"casb"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Validate(map[string][]byte{"policy.rego": []byte(c.src)}, data, c.roots)
+			if c.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || len(verr.Diagnostics) == 0 {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			d := verr.Diagnostics[0]
+			if d.Code != c.code || d.Row != c.row || d.File != "policy.rego" {
+				t.Fatalf("unexpected diagnostic: %+v", d)
+			}
+		})
+	}
+}
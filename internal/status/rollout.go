@@ -0,0 +1,92 @@
+package status
+
+import (
+	"net"
+	"net/url"
+)
+
+const (
+	StateActive  = "active"  // 최신 bundle 활성화
+	StateStale   = "stale"   // 이전 bundle 사용중
+	StateFailing = "failing" // bundle 다운로드/활성화 오류
+	StateUnknown = "unknown" // 상태 보고 없음
+)
+
+type Rollout struct {
+	Service   string          `json:"service"`
+	Latest    string          `json:"latest"`
+	Clients   []ClientRollout `json:"clients"`
+	Unmatched []Report        `json:"unmatched"` // 등록된 client와 연결되지 않은 instance
+	Summary   map[string]int  `json:"summary"`   // state => client 수
+}
+
+type ClientRollout struct {
+	Address   string   `json:"address"`
+	State     string   `json:"state"`
+	Instances []Report `json:"instances"`
+}
+
+// 등록된 client(webhook 주소)와 상태 보고를 연결하고 latest 버전과 비교
+// labels.client가 client 주소와 같거나, 보고한 IP가 client 주소의 host와 같으면 같은 client로 간주
+func NewRollout(service, latest string, clients []string, reports []Report) *Rollout {
+	r := &Rollout{
+		Service:   service,
+		Latest:    latest,
+		Clients:   []ClientRollout{},
+		Unmatched: []Report{},
+		Summary:   map[string]int{},
+	}
+
+	matched := make([]bool, len(reports))
+	for _, addr := range clients {
+		cr := ClientRollout{Address: addr, Instances: []Report{}}
+		host := clientHost(addr)
+		for i, rep := range reports {
+			if rep.Labels["client"] == addr || (host != "" && rep.RemoteIP == host) {
+				cr.Instances = append(cr.Instances, rep)
+				matched[i] = true
+			}
+		}
+		cr.State = clientState(latest, cr.Instances)
+		r.Summary[cr.State]++
+		r.Clients = append(r.Clients, cr)
+	}
+
+	for i, rep := range reports {
+		if !matched[i] {
+			r.Unmatched = append(r.Unmatched, rep)
+		}
+	}
+	return r
+}
+
+// 가장 나쁜 instance 상태 (failing > stale > active)
+func clientState(latest string, reports []Report) string {
+	if len(reports) == 0 {
+		return StateUnknown
+	}
+
+	state := StateActive
+	for _, rep := range reports {
+		switch {
+		case rep.Failing():
+			return StateFailing
+		case rep.ActiveRevision != latest:
+			state = StateStale
+		}
+	}
+	return state
+}
+
+// "http://10.0.0.5:8181" => "10.0.0.5"
+// hostname으로 등록된 client는 OPA labels.client로 연결
+func clientHost(addr string) string {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
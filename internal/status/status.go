@@ -0,0 +1,117 @@
+package status
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// OPA status plugin이 전송하는 상태 보고 (필요한 필드만)
+// https://www.openpolicyagent.org/docs/latest/management-status/
+type Status struct {
+	Labels  map[string]string        `json:"labels"`
+	Bundle  *BundleStatus            `json:"bundle,omitempty"` // OPA 구버전 (단일 bundle)
+	Bundles map[string]*BundleStatus `json:"bundles,omitempty"`
+}
+
+type BundleStatus struct {
+	Name                     string        `json:"name"`
+	ActiveRevision           string        `json:"active_revision,omitempty"`
+	LastSuccessfulActivation time.Time     `json:"last_successful_activation,omitempty"`
+	LastSuccessfulDownload   time.Time     `json:"last_successful_download,omitempty"`
+	LastRequest              time.Time     `json:"last_request,omitempty"`
+	Code                     string        `json:"code,omitempty"`
+	Message                  string        `json:"message,omitempty"`
+	Errors                   []BundleError `json:"errors,omitempty"`
+}
+
+type BundleError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// OPA instance별 마지막 bundle 상태
+type Report struct {
+	InstanceID               string            `json:"instance_id"` // labels.id
+	Labels                   map[string]string `json:"labels,omitempty"`
+	RemoteIP                 string            `json:"remote_ip"`
+	ActiveRevision           string            `json:"active_revision"`
+	LastSuccessfulActivation time.Time         `json:"last_successful_activation"`
+	LastSuccessfulDownload   time.Time         `json:"last_successful_download"`
+	Code                     string            `json:"code,omitempty"`
+	Message                  string            `json:"message,omitempty"`
+	Errors                   []BundleError     `json:"errors,omitempty"`
+	LastSeen                 time.Time         `json:"last_seen"`
+}
+
+func (r *Report) Failing() bool {
+	return r.Code != ""
+}
+
+// service => instance id => Report
+// 상태 보고는 주기적으로 다시 전송되므로 메모리에만 보관
+type Store struct {
+	reports map[string]map[string]*Report
+	mu      sync.RWMutex
+}
+
+func NewStore() *Store {
+	return &Store{reports: make(map[string]map[string]*Report)}
+}
+
+// 상태 보고의 bundle 중 이름이 service와 같은 것만 기록하고 기록된 service 목록 반환
+func (s *Store) Update(st *Status, remoteIP string, isService func(string) bool, now time.Time) []string {
+	bundles := st.Bundles
+	if len(bundles) == 0 && st.Bundle != nil {
+		bundles = map[string]*BundleStatus{st.Bundle.Name: st.Bundle}
+	}
+
+	id := st.Labels["id"]
+	if id == "" {
+		id = remoteIP
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var updated []string
+	for name, b := range bundles {
+		if b == nil || !isService(name) {
+			continue
+		}
+
+		if s.reports[name] == nil {
+			s.reports[name] = make(map[string]*Report)
+		}
+		s.reports[name][id] = &Report{
+			InstanceID:               id,
+			Labels:                   st.Labels,
+			RemoteIP:                 remoteIP,
+			ActiveRevision:           b.ActiveRevision,
+			LastSuccessfulActivation: b.LastSuccessfulActivation,
+			LastSuccessfulDownload:   b.LastSuccessfulDownload,
+			Code:                     b.Code,
+			Message:                  b.Message,
+			Errors:                   b.Errors,
+			LastSeen:                 now,
+		}
+		updated = append(updated, name)
+	}
+	sort.Strings(updated)
+	return updated
+}
+
+// service의 모든 instance 상태 (instance id 순)
+func (s *Store) Reports(service string) []Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := make([]Report, 0, len(s.reports[service]))
+	for _, r := range s.reports[service] {
+		reports = append(reports, *r)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].InstanceID < reports[j].InstanceID
+	})
+	return reports
+}
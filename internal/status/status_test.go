@@ -0,0 +1,61 @@
+package status
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStoreUpdate(t *testing.T) {
+	var st Status
+	err := json.Unmarshal([]byte(`{
+		"labels": {"id": "opa-1", "version": "0.70.0"},
+		"bundles": {
+			"casb": {"name": "casb", "active_revision": "v0.3"},
+			"other": {"name": "other", "active_revision": "v9.9"}
+		}
+	}`), &st)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	s := NewStore()
+	now := time.Now()
+	updated := s.Update(&st, "10.0.0.5", func(name string) bool { return name == "casb" }, now)
+	if len(updated) != 1 || updated[0] != "casb" {
+		t.Fatalf("unexpected updated services: %v", updated)
+	}
+
+	reports := s.Reports("casb")
+	if len(reports) != 1 || reports[0].InstanceID != "opa-1" || reports[0].ActiveRevision != "v0.3" || !reports[0].LastSeen.Equal(now) {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	if len(s.Reports("other")) != 0 {
+		t.Fatalf("unknown service should not be recorded")
+	}
+}
+
+func TestNewRollout(t *testing.T) {
+	reports := []Report{
+		{InstanceID: "a", RemoteIP: "10.0.0.1", ActiveRevision: "v0.3"},
+		{InstanceID: "b", RemoteIP: "10.0.0.2", ActiveRevision: "v0.2"},
+		{InstanceID: "c", RemoteIP: "10.0.0.9", Labels: map[string]string{"client": "http://opa-c:8181"}, Code: "bundle_error"},
+		{InstanceID: "d", RemoteIP: "10.0.0.10", ActiveRevision: "v0.3"},
+	}
+	clients := []string{"http://10.0.0.1:8181", "http://10.0.0.2:8181", "http://opa-c:8181", "http://10.0.0.3:8181"}
+
+	r := NewRollout("casb", "v0.3", clients, reports)
+
+	want := []string{StateActive, StateStale, StateFailing, StateUnknown}
+	for i, cr := range r.Clients {
+		if cr.State != want[i] {
+			t.Errorf("%s: expected %s, got %s", cr.Address, want[i], cr.State)
+		}
+	}
+	if len(r.Unmatched) != 1 || r.Unmatched[0].InstanceID != "d" {
+		t.Fatalf("unexpected unmatched: %+v", r.Unmatched)
+	}
+	if r.Summary[StateActive] != 1 || r.Summary[StateUnknown] != 1 {
+		t.Fatalf("unexpected summary: %v", r.Summary)
+	}
+}
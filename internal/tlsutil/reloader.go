@@ -0,0 +1,161 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 인증서 파일 변경 여부 확인 간격
+const reloadInterval = 5 * time.Second
+
+// 서버 인증서와 client CA를 파일 변경 시 다시 읽는 TLS 설정
+// 새 설정은 이후 handshake부터 적용되며 기존 연결은 유지
+type Reloader struct {
+	certFile   string
+	keyFile    string
+	caFile     string // 비어있으면 client 인증서를 검증하지 않음
+	clientAuth tls.ClientAuthType
+	logger     *zap.Logger
+
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+	checked time.Time
+
+	mu sync.Mutex
+}
+
+func NewReloader(logger *zap.Logger, certFile, keyFile, caFile string, clientAuth tls.ClientAuthType) (*Reloader, error) {
+	if clientAuth >= tls.VerifyClientCertIfGiven && caFile == "" {
+		return nil, fmt.Errorf("client_ca_file is required for client_auth %s", clientAuth)
+	}
+
+	r := &Reloader{
+		certFile:   certFile,
+		keyFile:    keyFile,
+		caFile:     caFile,
+		clientAuth: clientAuth,
+		logger:     logger,
+		modTime:    map[string]time.Time{},
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checked = time.Now()
+	return r, nil
+}
+
+// http.Server.TLSConfig (ListenAndServeTLS("", "")와 함께 사용)
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current(time.Now()), nil
+		},
+	}
+}
+
+func (r *Reloader) current(now time.Time) *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.checked) >= reloadInterval {
+		r.checked = now
+		if r.changed() {
+			// 읽기 실패 시(e.g. cert만 교체되고 key는 아직인 경우) 기존 인증서 유지
+			if err := r.load(); err != nil {
+				r.logger.Error("failed to reload TLS certificates", zap.Error(err))
+			} else {
+				r.logger.Info("TLS certificates reloaded", zap.String("cert", r.certFile))
+			}
+		}
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		ClientCAs:    r.pool,
+		ClientAuth:   r.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+}
+
+// caller가 r.mu를 잡고 있거나 생성 중이어야 함
+func (r *Reloader) changed() bool {
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(r.modTime[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// caller가 r.mu를 잡고 있거나 생성 중이어야 함
+func (r *Reloader) load() error {
+	modTime := map[string]time.Time{}
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		modTime[path] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", r.caFile)
+		}
+	}
+
+	r.cert = &cert
+	r.pool = pool
+	r.modTime = modTime
+	return nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+	return files
+}
+
+// config의 client_auth => tls.ClientAuthType
+func ParseClientAuth(s string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require_and_verify":
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("unknown client_auth: %q", s)
+}
@@ -0,0 +1,103 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func writeCert(t *testing.T, certFile, keyFile, cn string, modTime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, modTime, modTime); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+}
+
+func servedCN(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+
+	c, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	leaf, err := x509.ParseCertificate(c.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "old", time.Now().Add(-time.Hour))
+
+	r, err := NewReloader(zap.NewNop(), certFile, keyFile, "", tls.NoClientCert)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	cfg := r.TLSConfig()
+	if cn := servedCN(t, cfg); cn != "old" {
+		t.Fatalf("unexpected certificate: %s", cn)
+	}
+
+	writeCert(t, certFile, keyFile, "new", time.Now())
+	r.checked = time.Time{}
+	if cn := servedCN(t, cfg); cn != "new" {
+		t.Fatalf("certificate was not reloaded: %s", cn)
+	}
+
+	// 잘못된 파일로 교체된 경우 기존 인증서 유지
+	if err := os.WriteFile(keyFile, []byte("broken"), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+	r.checked = time.Time{}
+	if cn := servedCN(t, cfg); cn != "new" {
+		t.Fatalf("expected previous certificate to be kept: %s", cn)
+	}
+}
+
+func TestNewReloaderRequiresCA(t *testing.T) {
+	if _, err := NewReloader(zap.NewNop(), "a", "b", "", tls.RequireAndVerifyClientCert); err == nil {
+		t.Fatalf("expected error without client CA")
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	contextkey "github.com/jjhwan-h/bundle-server/api/context"
+	"github.com/jjhwan-h/bundle-server/internal/auth"
+	appErr "github.com/jjhwan-h/bundle-server/internal/errors"
+)
+
+// 요청 경로의 service에 role 이상의 권한이 있는 경우만 허용
+// :service가 없는 경로는 모든 service("*")에 대한 권한 필요
+// authn이 nil이면 인증하지 않음
+func Authorize(authn *auth.Authenticator, role auth.Role) gin.HandlerFunc {
+	return authorize(authn, role, func(id *auth.Identity, c *gin.Context) bool {
+		return id.Can(c.Param("service"), role)
+	})
+}
+
+// 어느 service든 role 이상의 권한이 있는 경우 허용 (OPA status, decision log 수신)
+func AuthorizeAny(authn *auth.Authenticator, role auth.Role) gin.HandlerFunc {
+	return authorize(authn, role, func(id *auth.Identity, c *gin.Context) bool {
+		return id.CanAny(role)
+	})
+}
+
+func authorize(authn *auth.Authenticator, role auth.Role, allowed func(*auth.Identity, *gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authn == nil {
+			c.Next()
+			return
+		}
+
+		var (
+			id  *auth.Identity
+			err error
+		)
+		header := c.GetHeader("Authorization")
+		if certID, ok := authn.AuthenticateCert(c.Request.TLS); ok && header == "" {
+			id = certID
+		} else {
+			id, err = authn.Authenticate(header)
+		}
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="bundle-server"`)
+			c.Error(appErr.NewHttpError("unauthorized", http.StatusUnauthorized, err.Error()))
+			c.Abort()
+			return
+		}
+		c.Set(contextkey.Identity, id)
+
+		if !allowed(id, c) {
+			err := fmt.Errorf("%w: %s requires role %s", auth.ErrForbidden, id.Subject, role)
+			c.Error(appErr.NewHttpError("forbidden", http.StatusForbidden, err.Error()))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	contextkey "github.com/jjhwan-h/bundle-server/api/context"
 )
 
 func TimeOutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set(contextkey.RequestContext, c.Request.Context())
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 